@@ -27,6 +27,10 @@ func (s *BlockchainService) ProcessPaymentEvent(ctx context.Context, payload []b
 		logger.Error("failed to parse payment event")
 		return err
 	}
+	// the payments channel also carries refunds; only completions go on chain
+	if evt.Event != "payment.completed" {
+		return nil
+	}
 
 	logger.Info("processing payment event", logger.WithFields())
 	
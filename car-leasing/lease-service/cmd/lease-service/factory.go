@@ -0,0 +1,148 @@
+package main
+
+import (
+    "time"
+
+    "leaseCar/lease-service/internal/adapters"
+    "leaseCar/lease-service/internal/controllers"
+    "leaseCar/lease-service/internal/pricing"
+    "leaseCar/lease-service/internal/repositories"
+    "leaseCar/lease-service/internal/services"
+    "leaseCar/utils/logger"
+    redisutil "leaseCar/utils/redis"
+
+    "github.com/jackc/pgx/v5/pgxpool"
+    meilisearch "github.com/meilisearch/meilisearch-go"
+)
+
+// Factory functions for dependency injection
+func NewLeaseRepository(pool *pgxpool.Pool) *repositories.LeaseRepository {
+    return repositories.NewLeaseRepository(pool)
+}
+
+func NewLeasePaymentRepository(pool *pgxpool.Pool) *repositories.LeasePaymentRepository {
+    return repositories.NewLeasePaymentRepository(pool)
+}
+
+func NewVehicleRepository(pool *pgxpool.Pool) *repositories.VehicleRepository {
+    return repositories.NewVehicleRepository(pool)
+}
+
+func NewOdometerRepository(pool *pgxpool.Pool) *repositories.OdometerRepository {
+    return repositories.NewOdometerRepository(pool)
+}
+
+func NewContractRepository(pool *pgxpool.Pool) *repositories.ContractRepository {
+    return repositories.NewContractRepository(pool)
+}
+
+func NewCreditDecisionRepository(pool *pgxpool.Pool) *repositories.CreditDecisionRepository {
+    return repositories.NewCreditDecisionRepository(pool)
+}
+
+func NewInspectionRepository(pool *pgxpool.Pool) *repositories.InspectionRepository {
+    return repositories.NewInspectionRepository(pool)
+}
+
+func NewPricingCalculator(conf pricing.Config) *pricing.Calculator {
+    return pricing.NewCalculator(conf)
+}
+
+func NewMeiliAdapter(c *meilisearch.Client) *adapters.MeiliAdapter {
+    return adapters.NewMeiliAdapter(c)
+}
+
+// NewCreditBureau returns the bureau named in the credit config, or nil
+// when none is configured.
+func NewCreditBureau(name string) services.CreditBureau {
+    switch name {
+    case "stub":
+        logger.Warn("credit decisions use the stub bureau; do not run this in production")
+        return adapters.NewStubCreditBureau()
+    case "":
+        return nil
+    default:
+        logger.Warn("unknown credit bureau " + name + "; leases go to manual review")
+        return nil
+    }
+}
+
+// NewCreditDecisioner scores with the rules when a bureau is available and
+// otherwise fails closed to manual review.
+func NewCreditDecisioner(bureau services.CreditBureau, conf services.CreditConfig) services.CreditDecisioner {
+    if bureau == nil {
+        return services.NewManualReviewDecisioner()
+    }
+    return services.NewRulesDecisioner(bureau, conf)
+}
+
+func NewCreditService(d services.CreditDecisioner, decisions *repositories.CreditDecisionRepository) *services.CreditService {
+    return services.NewCreditService(d, decisions)
+}
+
+func NewLeaseIndexer(repo *repositories.LeaseRepository, meili *adapters.MeiliAdapter, interval time.Duration) *services.LeaseIndexer {
+    return services.NewLeaseIndexer(repo, meili, interval)
+}
+
+func NewLeaseService(repo *repositories.LeaseRepository, payments *repositories.LeasePaymentRepository, vehicles *repositories.VehicleRepository,
+    calc *pricing.Calculator, meili *adapters.MeiliAdapter, indexer *services.LeaseIndexer, mileage *services.MileageService, holds *services.ReservationService, credit *services.CreditService,
+    inspections *services.InspectionService, r *redisutil.Client) *services.LeaseService {
+    return services.NewLeaseService(repo, payments, vehicles, calc, meili, indexer, mileage, holds, credit, inspections, r)
+}
+
+func NewLeaseScheduler(svc *services.LeaseService, repo *repositories.LeaseRepository, payments *repositories.LeasePaymentRepository, calc *pricing.Calculator, r *redisutil.Client, interval time.Duration) *services.LeaseScheduler {
+    return services.NewLeaseScheduler(svc, repo, payments, calc, r, interval)
+}
+
+func NewLeaseController(svc *services.LeaseService) *controllers.LeaseController {
+    return controllers.NewLeaseController(svc)
+}
+
+func NewVehicleIndexer(repo *repositories.VehicleRepository, meili *adapters.MeiliAdapter, interval time.Duration) *services.VehicleIndexer {
+    return services.NewVehicleIndexer(repo, meili, interval)
+}
+
+func NewVehicleService(repo *repositories.VehicleRepository, indexer *services.VehicleIndexer, holds *services.ReservationService) *services.VehicleService {
+    return services.NewVehicleService(repo, indexer, holds)
+}
+
+func NewVehicleController(svc *services.VehicleService) *controllers.VehicleController {
+    return controllers.NewVehicleController(svc)
+}
+
+func NewReservationService(vehicles *repositories.VehicleRepository, leases *repositories.LeaseRepository, r *redisutil.Client) *services.ReservationService {
+    return services.NewReservationService(vehicles, leases, r)
+}
+
+func NewReservationController(svc *services.ReservationService) *controllers.ReservationController {
+    return controllers.NewReservationController(svc)
+}
+
+func NewContractService(leases *repositories.LeaseRepository, vehicles *repositories.VehicleRepository, payments *repositories.LeasePaymentRepository,
+    contracts *repositories.ContractRepository) *services.ContractService {
+    return services.NewContractService(leases, vehicles, payments, contracts)
+}
+
+func NewContractController(svc *services.ContractService) *controllers.ContractController {
+    return controllers.NewContractController(svc)
+}
+
+func NewInspectionService(leases *repositories.LeaseRepository, inspections *repositories.InspectionRepository) *services.InspectionService {
+    return services.NewInspectionService(leases, inspections)
+}
+
+func NewInspectionController(svc *services.InspectionService) *controllers.InspectionController {
+    return controllers.NewInspectionController(svc)
+}
+
+func NewAdminController(indexer *services.LeaseIndexer) *controllers.AdminController {
+    return controllers.NewAdminController(indexer)
+}
+
+func NewMileageService(leases *repositories.LeaseRepository, readings *repositories.OdometerRepository, calc *pricing.Calculator, r *redisutil.Client) *services.MileageService {
+    return services.NewMileageService(leases, readings, calc, r)
+}
+
+func NewMileageController(svc *services.MileageService) *controllers.MileageController {
+    return controllers.NewMileageController(svc)
+}
@@ -7,6 +7,9 @@ import (
     "os"
     "time"
 
+    "leaseCar/lease-service/internal/controllers"
+    "leaseCar/lease-service/internal/pricing"
+    "leaseCar/lease-service/internal/services"
     cfg "leaseCar/utils/config"
     "leaseCar/utils/logger"
     redisutil "leaseCar/utils/redis"
@@ -29,6 +32,18 @@ func main() {
 
     logger.Info("config loaded")
 
+    pricingConf := pricing.DefaultConfig()
+    if err := cfg.LoadKey(configPath, "pricing", &pricingConf); err != nil {
+        log.Fatalf("failed to load pricing config: %v", err)
+    }
+    if err := pricingConf.Validate(); err != nil {
+        log.Fatalf("invalid pricing config: %v", err)
+    }
+    creditConf := services.DefaultCreditConfig()
+    if err := cfg.LoadKey(configPath, "credit", &creditConf); err != nil {
+        log.Fatalf("failed to load credit config: %v", err)
+    }
+
     // setup DB
     dbUrl := fmt.Sprintf("postgres://%s:%s@%s:%d/%s",
         conf.Database.User, conf.Database.Password, conf.Database.Host, conf.Database.Port, conf.Database.DBName)
@@ -62,14 +77,78 @@ func main() {
     // wire components
     // create repository and service
     repo := NewLeaseRepository(pool)
+    paymentRepo := NewLeasePaymentRepository(pool)
+    vehicleRepo := NewVehicleRepository(pool)
+    odometerRepo := NewOdometerRepository(pool)
+    contractRepo := NewContractRepository(pool)
+    calc := NewPricingCalculator(pricingConf)
     meili := NewMeiliAdapter(meiliClient)
-    svc := NewLeaseService(repo, meili)
+    go func() {
+        ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+        defer cancel()
+        if err := meili.ConfigureIndex(ctx, "leases", services.LeaseFilterableAttributes, services.LeaseSortableAttributes); err != nil {
+            logger.Warn("failed to configure leases index")
+        }
+    }()
+    indexer := NewLeaseIndexer(repo, meili, 10*time.Second)
+    go indexer.Run(context.Background())
+    mileageSvc := NewMileageService(repo, odometerRepo, calc, r)
+    reservationSvc := NewReservationService(vehicleRepo, repo, r)
+    creditSvc := NewCreditService(NewCreditDecisioner(NewCreditBureau(creditConf.Bureau), creditConf), NewCreditDecisionRepository(pool))
+    inspectionSvc := NewInspectionService(repo, NewInspectionRepository(pool))
+    svc := NewLeaseService(repo, paymentRepo, vehicleRepo, calc, meili, indexer, mileageSvc, reservationSvc, creditSvc, inspectionSvc, r)
+    scheduler := NewLeaseScheduler(svc, repo, paymentRepo, calc, r, time.Minute)
+    go scheduler.Run(context.Background())
     controller := NewLeaseController(svc)
+    vehicleIndexer := NewVehicleIndexer(vehicleRepo, meili, 10*time.Second)
+    go vehicleIndexer.Run(context.Background())
+    vehicleSvc := NewVehicleService(vehicleRepo, vehicleIndexer, reservationSvc)
+    vehicleController := NewVehicleController(vehicleSvc)
+    adminController := NewAdminController(indexer)
+    mileageController := NewMileageController(mileageSvc)
+    reservationController := NewReservationController(reservationSvc)
+    inspectionController := NewInspectionController(inspectionSvc)
+    contractController := NewContractController(NewContractService(repo, vehicleRepo, paymentRepo, contractRepo))
 
     // routes
+    idParam := controllers.UUIDParam("id")
     app.Post("/leases", controller.Create)
-    app.Get("/leases/:id", controller.GetByID)
+    app.Post("/leases/quote", controller.Quote)
+    app.Get("/leases/:id", idParam, controller.GetByID)
     app.Get("/leases", controller.Search)
+    app.Get("/users/:id/leases", idParam, controller.ListByUser)
+    app.Get("/leases/:id/schedule", idParam, controller.Schedule)
+    app.Get("/leases/:id/balance", idParam, controller.Balance)
+    app.Get("/leases/:id/contract.pdf", idParam, contractController.PDF)
+    app.Post("/leases/:id/contract", idParam, contractController.Issue)
+    app.Post("/leases/:id/submit", idParam, controller.Submit)
+    app.Post("/leases/:id/approve", idParam, controller.Approve)
+    app.Post("/leases/:id/reject", idParam, controller.Reject)
+    app.Post("/leases/:id/activate", idParam, controller.Activate)
+    app.Post("/leases/:id/complete", idParam, controller.Complete)
+    app.Post("/leases/:id/cancel", idParam, controller.Cancel)
+    app.Post("/leases/:id/extend", idParam, controller.Extend)
+    app.Post("/leases/:id/renew", idParam, controller.Renew)
+    app.Post("/leases/:id/amendments", idParam, controller.Amend)
+    app.Get("/leases/:id/history", idParam, controller.History)
+    app.Get("/leases/:id/credit-decisions", idParam, controller.CreditDecisions)
+    app.Post("/leases/:id/odometer", idParam, mileageController.RecordReading)
+    app.Get("/leases/:id/mileage", idParam, mileageController.Report)
+    app.Post("/leases/:id/inspection", idParam, inspectionController.Record)
+    app.Get("/leases/:id/inspection", idParam, inspectionController.Get)
+    app.Get("/leases/:id/termination-quote", idParam, controller.TerminationQuote)
+    app.Post("/leases/:id/terminate", idParam, controller.Terminate)
+
+    app.Post("/vehicles", vehicleController.Create)
+    app.Get("/vehicles", vehicleController.List)
+    app.Get("/vehicles/:id", idParam, vehicleController.GetByID)
+    app.Patch("/vehicles/:id", idParam, vehicleController.Update)
+    app.Delete("/vehicles/:id", idParam, vehicleController.Delete)
+    app.Post("/vehicles/:id/hold", idParam, reservationController.Hold)
+    app.Get("/vehicles/:id/hold", idParam, reservationController.Get)
+    app.Delete("/vehicles/:id/hold", idParam, reservationController.Release)
+
+    app.Post("/admin/reindex/leases", adminController.ReindexLeases)
 
     port := conf.Server.Port
     logger.Info("lease-service starting", logger.WithFields())
@@ -6,6 +6,7 @@ require (
     github.com/gofiber/fiber/v2 v2.46.0
     github.com/jackc/pgx/v5 v5.10.0
     github.com/meilisearch/meilisearch-go v0.1.1
+    go.uber.org/zap v1.26.0
 )
 
 replace leaseCar/utils => ../utils
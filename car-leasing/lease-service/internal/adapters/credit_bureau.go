@@ -0,0 +1,25 @@
+package adapters
+
+import (
+    "context"
+    "crypto/sha256"
+    "encoding/binary"
+
+    "leaseCar/lease-service/internal/dtos"
+)
+
+// StubCreditBureau stands in for a real bureau integration. Reports are
+// derived from a hash of the user id, so the same user always gets the same
+// score (300-850) and monthly income (2,000-14,000) across runs and tests.
+type StubCreditBureau struct{}
+
+func NewStubCreditBureau() *StubCreditBureau {
+    return &StubCreditBureau{}
+}
+
+func (b *StubCreditBureau) Report(ctx context.Context, userID string) (*dtos.CreditReport, error) {
+    sum := sha256.Sum256([]byte(userID))
+    score := 300 + int(binary.BigEndian.Uint16(sum[0:2])%551)
+    income := 2000 + float64(binary.BigEndian.Uint16(sum[2:4])%121)*100
+    return &dtos.CreditReport{UserID: userID, Score: score, MonthlyIncome: income, Source: "stub"}, nil
+}
@@ -2,6 +2,8 @@ package adapters
 
 import (
     "context"
+    "fmt"
+    "time"
 
     meili "github.com/meilisearch/meilisearch-go"
 )
@@ -14,12 +16,59 @@ func NewMeiliAdapter(c *meili.Client) *MeiliAdapter {
     return &MeiliAdapter{client: c}
 }
 
+// IndexLease adds or replaces documents and waits until MeiliSearch has
+// applied them, so a nil error means the documents are searchable.
 func (m *MeiliAdapter) IndexLease(ctx context.Context, index string, doc interface{}) error {
-    _, err := m.client.Index(index).AddDocuments(doc)
-    return err
+    info, err := m.client.Index(index).AddDocuments(doc)
+    if err != nil {
+        return err
+    }
+    return m.waitForTask(ctx, info)
+}
+
+// IndexVehicle adds or replaces documents and waits until MeiliSearch has
+// applied them, so a nil error means the documents are searchable.
+func (m *MeiliAdapter) IndexVehicle(ctx context.Context, index string, doc interface{}) error {
+    info, err := m.client.Index(index).AddDocuments(doc)
+    if err != nil {
+        return err
+    }
+    return m.waitForTask(ctx, info)
+}
+
+// DeleteDocuments removes documents by id and waits until MeiliSearch has
+// applied the deletion.
+func (m *MeiliAdapter) DeleteDocuments(ctx context.Context, index string, ids []string) error {
+    info, err := m.client.Index(index).DeleteDocuments(ids)
+    if err != nil {
+        return err
+    }
+    return m.waitForTask(ctx, info)
 }
 
-func (m *MeiliAdapter) Search(ctx context.Context, index, q string, limit int) (*meili.SearchResponse, error) {
-    res, err := m.client.Index(index).Search(q, &meili.SearchRequest{Limit: int64(limit)})
+func (m *MeiliAdapter) Search(ctx context.Context, index, q string, req *meili.SearchRequest) (*meili.SearchResponse, error) {
+    res, err := m.client.Index(index).Search(q, req)
     return res, err
 }
+
+// ConfigureIndex sets the filterable and sortable attributes of an index.
+func (m *MeiliAdapter) ConfigureIndex(ctx context.Context, index string, filterable, sortable []string) error {
+    if _, err := m.client.Index(index).UpdateFilterableAttributes(&filterable); err != nil {
+        return err
+    }
+    _, err := m.client.Index(index).UpdateSortableAttributes(&sortable)
+    return err
+}
+
+// waitForTask blocks until an enqueued task finishes and fails unless it
+// succeeded.
+func (m *MeiliAdapter) waitForTask(ctx context.Context, info *meili.TaskInfo) error {
+    task, err := m.client.WaitForTask(info.TaskUID, meili.WaitParams{Context: ctx, Interval: 100 * time.Millisecond})
+    if err != nil {
+        return err
+    }
+    if task.Status != meili.TaskStatusSucceeded {
+        return fmt.Errorf("meilisearch task %d %s: %s", info.TaskUID, task.Status, task.Error.Message)
+    }
+    return nil
+}
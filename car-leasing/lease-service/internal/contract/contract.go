@@ -0,0 +1,105 @@
+// Package contract renders lease contracts as PDF documents.
+package contract
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    "leaseCar/lease-service/internal/dtos"
+)
+
+// Data is everything printed on a contract. Schedule is the lease's
+// installments, or the projected ones when Projected is set (leases that
+// have not been activated yet).
+type Data struct {
+    Lease     *dtos.Lease
+    Vehicle   *dtos.Vehicle
+    Lessee    *dtos.ContractParty
+    Schedule  []dtos.LeasePayment
+    Projected bool
+}
+
+// Render produces the contract PDF. Output depends only on d, so rendering
+// the same lease version twice yields identical bytes (and hash).
+func Render(d *Data) []byte {
+    l, v := d.Lease, d.Vehicle
+    doc := newDocument()
+
+    doc.line(bold, 18, "Vehicle Lease Agreement")
+    doc.line(regular, 9, "Lease "+l.ID+" - version "+l.UpdatedAt.UTC().Format(time.RFC3339))
+    doc.rule()
+
+    doc.space(6)
+    doc.line(bold, 12, "1. Parties")
+    doc.line(regular, 10, "Lessor: leaseCar")
+    lessee := d.Lessee.Name
+    if lessee == "" {
+        lessee = d.Lessee.Email
+    }
+    doc.line(regular, 10, "Lessee: "+lessee+" <"+d.Lessee.Email+">")
+    if d.Lessee.Phone != "" {
+        doc.line(regular, 10, "Phone: "+d.Lessee.Phone)
+    }
+    doc.line(regular, 10, "Customer ID: "+d.Lessee.UserID)
+
+    doc.space(6)
+    doc.line(bold, 12, "2. Vehicle")
+    doc.line(regular, 10, fmt.Sprintf("%d %s %s (%s)", v.Year, v.Make, v.Model, strings.ToLower(v.VehicleType)))
+    doc.line(regular, 10, "VIN: "+v.VIN)
+    if v.LicensePlate != nil {
+        doc.line(regular, 10, "License plate: "+*v.LicensePlate)
+    }
+    if v.Color != nil {
+        doc.line(regular, 10, "Color: "+*v.Color)
+    }
+    doc.line(regular, 10, fmt.Sprintf("Odometer at listing: %d miles", v.Mileage))
+
+    doc.space(6)
+    doc.line(bold, 12, "3. Lease terms")
+    doc.line(regular, 10, "Term: "+date(l.StartDate)+" to "+date(l.EndDate))
+    doc.line(regular, 10, "Monthly payment: "+money(l.Monthly))
+    doc.line(regular, 10, "Security deposit: "+money(l.Deposit))
+    doc.line(regular, 10, "Total cost: "+money(l.TotalCost))
+    doc.line(regular, 10, fmt.Sprintf("Mileage allowance: %d miles", l.MileageLimit))
+    if p := l.Pricing; p != nil {
+        doc.line(regular, 10, fmt.Sprintf("Capitalized cost %s, fees %s, residual value %s, money factor %.5f",
+            money(p.AdjustedCapCost), money(p.Fees), money(p.ResidualValue), p.MoneyFactor))
+    }
+    doc.paragraph(regular, 10, "Miles driven beyond the allowance, late payments and early termination are "+
+        "charged according to the lessor's published fee schedule in effect on the date of this agreement.")
+
+    doc.space(6)
+    title := "4. Payment schedule"
+    if d.Projected {
+        title += " (projected; confirmed on activation)"
+    }
+    doc.line(bold, 12, title)
+    cols := []float64{0, 40, 150, alignRight}
+    doc.row(bold, 10, cols, "#", "Due date", "Item", "Amount")
+    for _, p := range d.Schedule {
+        item := "Installment"
+        if p.Description != "" {
+            item = p.Description
+        }
+        doc.row(regular, 10, cols, fmt.Sprint(p.PaymentNumber), date(p.DueDate), item, money(p.Amount))
+    }
+
+    doc.space(18)
+    doc.line(bold, 12, "5. Signatures")
+    doc.paragraph(regular, 10, "By signing below the parties agree to the terms above.")
+    for _, party := range []string{"Lessee", "Lessor"} {
+        doc.space(30)
+        doc.row(regular, 10, []float64{0, 280}, "______________________________", "____________________")
+        doc.row(regular, 9, []float64{0, 280}, party+" signature", "Date")
+    }
+    return doc.bytes()
+}
+
+func date(t time.Time) string {
+    return t.Format("2006-01-02")
+}
+
+func money(v float64) string {
+    return fmt.Sprintf("%.2f", v)
+}
@@ -0,0 +1,74 @@
+package contract
+
+import (
+    "bytes"
+    "testing"
+    "time"
+
+    "leaseCar/lease-service/internal/dtos"
+)
+
+func testData() *Data {
+    day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 0, 0, 0, 0, time.UTC) }
+    plate := "7ABC123"
+    return &Data{
+        Lease: &dtos.Lease{ID: "lease-1", UserID: "user-1", VehicleID: "vehicle-1", StartDate: day(1, 1), EndDate: day(4, 1),
+            Monthly: 317.15, Deposit: 2000, TotalCost: 2951.45, MileageLimit: 12000, UpdatedAt: day(1, 1)},
+        Vehicle: &dtos.Vehicle{Year: 2024, Make: "Škoda", Model: "Octavia", VehicleType: "SEDAN", VIN: "TMBJJ7NE0L0000001", LicensePlate: &plate},
+        Lessee:  &dtos.ContractParty{UserID: "user-1", Name: "Zoë (Łucja) Nowak", Email: "zoe@example.com"},
+        Schedule: []dtos.LeasePayment{
+            {PaymentNumber: 1, DueDate: day(1, 1), Amount: 317.15},
+            {PaymentNumber: 2, DueDate: day(2, 1), Amount: 317.15},
+            {PaymentNumber: 3, DueDate: day(3, 1), Amount: 317.15},
+        },
+        Projected: true,
+    }
+}
+
+func TestRenderDeterministic(t *testing.T) {
+    first := Render(testData())
+    for i := 0; i < 3; i++ {
+        if again := Render(testData()); !bytes.Equal(first, again) {
+            t.Fatalf("render %d differs from the first", i+2)
+        }
+    }
+    if !bytes.HasPrefix(first, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(first, []byte("%%EOF\n")) {
+        t.Fatalf("not a PDF: %q...", first[:16])
+    }
+
+    changed := testData()
+    changed.Lease.UpdatedAt = changed.Lease.UpdatedAt.Add(time.Second)
+    if bytes.Equal(first, Render(changed)) {
+        t.Fatal("a new lease version rendered the same document")
+    }
+}
+
+func TestRenderPaginates(t *testing.T) {
+    d := testData()
+    for i := 4; i <= 60; i++ {
+        d.Schedule = append(d.Schedule, dtos.LeasePayment{PaymentNumber: i, DueDate: d.Lease.StartDate.AddDate(0, i-1, 0), Amount: 317.15})
+    }
+    if n := bytes.Count(Render(d), []byte("/Type /Page ")); n < 2 {
+        t.Fatalf("got %d pages for a 60-month schedule, want several", n)
+    }
+}
+
+func TestEscape(t *testing.T) {
+    tests := []struct {
+        in, want string
+    }{
+        {"Plain text", "Plain text"},
+        {`a (b) \c`, `a \(b\) \\c`},
+        {"tab\there", "tab here"},
+        {"Zoë", `Zo\353`},
+        {"Škoda – 5 €", `\212koda \226 5 \200`},
+        {"Łucja Dvořák", `Lucja Dvor\341k`},
+        {"Ștefan", "Stefan"},
+        {"Иван", "????"},
+    }
+    for _, tt := range tests {
+        if got := escape(tt.in); got != tt.want {
+            t.Errorf("escape(%q) = %q, want %q", tt.in, got, tt.want)
+        }
+    }
+}
@@ -0,0 +1,205 @@
+package contract
+
+import (
+    "bytes"
+    "fmt"
+    "strings"
+)
+
+// A minimal PDF 1.4 writer for text documents. It only knows the two
+// standard Helvetica fonts, so no font files are embedded, and its output is
+// deterministic: the same calls always produce the same bytes.
+
+const (
+    pageWidth  = 612.0 // US Letter, in points
+    pageHeight = 792.0
+    margin     = 54.0
+)
+
+// charWidth approximates Helvetica's average glyph width as a share of the
+// font size; it is only used to wrap and right-align text.
+const charWidth = 0.5
+
+type font string
+
+const (
+    regular font = "F1"
+    bold    font = "F2"
+)
+
+type document struct {
+    pages []*bytes.Buffer
+    y     float64
+}
+
+func newDocument() *document {
+    d := &document{}
+    d.newPage()
+    return d
+}
+
+func (d *document) newPage() {
+    d.pages = append(d.pages, &bytes.Buffer{})
+    d.y = pageHeight - margin
+}
+
+func (d *document) page() *bytes.Buffer {
+    return d.pages[len(d.pages)-1]
+}
+
+// ensure starts a new page unless h more points fit on the current one.
+func (d *document) ensure(h float64) {
+    if d.y-h < margin {
+        d.newPage()
+    }
+}
+
+func (d *document) text(x, y float64, f font, size float64, s string) {
+    fmt.Fprintf(d.page(), "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", f, size, x, y, escape(s))
+}
+
+// line writes one line of text at the left margin and moves down.
+func (d *document) line(f font, size float64, s string) {
+    lead := size * 1.4
+    d.ensure(lead)
+    d.y -= lead
+    d.text(margin, d.y, f, size, s)
+}
+
+// paragraph writes s wrapped to the page width.
+func (d *document) paragraph(f font, size float64, s string) {
+    maxChars := int((pageWidth - 2*margin) / (size * charWidth))
+    for _, l := range wrap(s, maxChars) {
+        d.line(f, size, l)
+    }
+}
+
+// alignRight as a row offset right-aligns the cell to the right margin.
+const alignRight = -1.0
+
+// row writes cells at the given x offsets from the left margin.
+func (d *document) row(f font, size float64, offsets []float64, cells ...string) {
+    lead := size * 1.4
+    d.ensure(lead)
+    d.y -= lead
+    for i, c := range cells {
+        x := margin + offsets[i]
+        if offsets[i] == alignRight {
+            x = pageWidth - margin - float64(len(c))*size*charWidth
+        }
+        d.text(x, d.y, f, size, c)
+    }
+}
+
+// rule draws a horizontal line across the page.
+func (d *document) rule() {
+    d.ensure(8)
+    d.y -= 6
+    fmt.Fprintf(d.page(), "0.5 w %.2f %.2f m %.2f %.2f l S\n", margin, d.y, pageWidth-margin, d.y)
+}
+
+func (d *document) space(h float64) {
+    d.ensure(h)
+    d.y -= h
+}
+
+// bytes serializes the document. Objects are laid out as: catalog, page
+// tree, the two fonts, then a page and content stream per page.
+func (d *document) bytes() []byte {
+    var out bytes.Buffer
+    var offsets []int
+    obj := func(body string) {
+        offsets = append(offsets, out.Len())
+        fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+    }
+
+    out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+    kids := make([]string, len(d.pages))
+    for i := range d.pages {
+        kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+    }
+    obj("<< /Type /Catalog /Pages 2 0 R >>")
+    obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+    obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+    obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+    for i, p := range d.pages {
+        obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+            pageWidth, pageHeight, 6+2*i))
+        obj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.Len(), p.String()))
+    }
+
+    xref := out.Len()
+    fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+    for _, off := range offsets {
+        fmt.Fprintf(&out, "%010d 00000 n \n", off)
+    }
+    fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+    return out.Bytes()
+}
+
+// escape makes s safe inside a PDF string literal and encodes it as
+// WinAnsi, the encoding of the standard fonts. Latin letters outside it
+// lose their diacritics (Ł -> L, ő -> o); only scripts the standard fonts
+// have no glyphs for at all become '?'.
+func escape(s string) string {
+    var b strings.Builder
+    for _, r := range s {
+        switch {
+        case r == '(' || r == ')' || r == '\\':
+            b.WriteByte('\\')
+            b.WriteRune(r)
+        case r < 32:
+            b.WriteByte(' ')
+        case r < 128:
+            b.WriteRune(r)
+        case r >= 160 && r < 256:
+            fmt.Fprintf(&b, "\\%03o", r)
+        case winAnsi[r] != 0:
+            fmt.Fprintf(&b, "\\%03o", winAnsi[r])
+        case r >= 0x100 && r < 0x180:
+            b.WriteByte(latinExtendedA[r-0x100])
+        case latinFold[r] != 0:
+            b.WriteByte(latinFold[r])
+        case r < 160:
+            b.WriteByte(' ')
+        default:
+            b.WriteByte('?')
+        }
+    }
+    return b.String()
+}
+
+// winAnsi maps the characters WinAnsiEncoding places in 0x80-0x9F.
+var winAnsi = map[rune]byte{
+    '€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+    '‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e, '‘': 0x91, '’': 0x92, '“': 0x93,
+    '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b,
+    'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+// latinExtendedA holds the base letter of every character in U+0100-U+017F.
+const latinExtendedA = "AaAaAaCcCcCcCcDdDdEeEeEeEeEeGgGgGgGgHhHhIiIiIiIiIiIiJjKkkLlLlLlLlLlNnNnNnnNnOoOoOoOoRrRrRrSsSsSsSsTtTtTtUuUuUuUuUuUuWwYyYZzZzZzs"
+
+// latinFold covers the few letters used in names beyond Latin Extended-A.
+var latinFold = map[rune]byte{'Ș': 'S', 'ș': 's', 'Ț': 'T', 'ț': 't', 'Ə': 'E', 'ə': 'e'}
+
+// wrap splits s into lines of at most width characters, breaking on spaces.
+func wrap(s string, width int) []string {
+    var lines []string
+    line := ""
+    for _, w := range strings.Fields(s) {
+        switch {
+        case line == "":
+            line = w
+        case len(line)+1+len(w) <= width:
+            line += " " + w
+        default:
+            lines = append(lines, line)
+            line = w
+        }
+    }
+    if line != "" {
+        lines = append(lines, line)
+    }
+    return lines
+}
@@ -0,0 +1,28 @@
+package controllers
+
+import (
+    "errors"
+
+    "github.com/gofiber/fiber/v2"
+    "leaseCar/lease-service/internal/services"
+)
+
+type AdminController struct {
+    indexer *services.LeaseIndexer
+}
+
+func NewAdminController(i *services.LeaseIndexer) *AdminController {
+    return &AdminController{indexer: i}
+}
+
+// ReindexLeases starts a background rebuild of the "leases" index.
+func (c *AdminController) ReindexLeases(ctx *fiber.Ctx) error {
+    err := c.indexer.StartReindex()
+    if errors.Is(err, services.ErrReindexRunning) {
+        return ctx.Status(409).JSON(fiber.Map{"error": err.Error()})
+    }
+    if err != nil {
+        return internalError(ctx, err)
+    }
+    return ctx.Status(202).JSON(fiber.Map{"status": "reindex started"})
+}
@@ -0,0 +1,51 @@
+package controllers
+
+import (
+    "context"
+    "errors"
+
+    "github.com/gofiber/fiber/v2"
+    "leaseCar/lease-service/internal/services"
+)
+
+type ContractController struct {
+    svc *services.ContractService
+}
+
+func NewContractController(s *services.ContractService) *ContractController {
+    return &ContractController{svc: s}
+}
+
+// PDF serves the lease contract with its SHA-256 in X-Contract-SHA256.
+// Serving it records nothing; see Issue.
+func (c *ContractController) PDF(ctx *fiber.Ctx) error {
+    pdf, err := c.svc.Render(context.Background(), ctx.Params("id"))
+    if err != nil {
+        return contractError(ctx, err)
+    }
+    ctx.Set(fiber.HeaderContentType, "application/pdf")
+    ctx.Set(fiber.HeaderContentDisposition, `inline; filename="lease-`+ctx.Params("id")+`.pdf"`)
+    ctx.Set("X-Contract-SHA256", services.ContractSHA256(pdf))
+    return ctx.Send(pdf)
+}
+
+// Issue records the SHA-256 of the lease's current contract in
+// lease_contracts. Issuing an unchanged contract again returns the
+// existing record.
+func (c *ContractController) Issue(ctx *fiber.Ctx) error {
+    record, err := c.svc.Issue(context.Background(), ctx.Params("id"))
+    if err != nil {
+        return contractError(ctx, err)
+    }
+    return ctx.Status(201).JSON(record)
+}
+
+func contractError(ctx *fiber.Ctx, err error) error {
+    switch {
+    case errors.Is(err, services.ErrLeaseNotFound):
+        return ctx.Status(404).JSON(fiber.Map{"error": "not found"})
+    case errors.Is(err, services.ErrVehicleNotFound), errors.Is(err, services.ErrLesseeNotFound):
+        return ctx.Status(409).JSON(fiber.Map{"error": err.Error()})
+    }
+    return internalError(ctx, err)
+}
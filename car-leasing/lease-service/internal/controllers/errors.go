@@ -0,0 +1,18 @@
+package controllers
+
+import (
+    "github.com/gofiber/fiber/v2"
+    "leaseCar/lease-service/internal/validation"
+    "leaseCar/utils/logger"
+)
+
+// internalError logs err and answers 500 without exposing its details.
+func internalError(ctx *fiber.Ctx, err error) error {
+    logger.Error(ctx.Method() + " " + ctx.Path() + ": " + err.Error())
+    return ctx.Status(500).JSON(fiber.Map{"error": "internal error"})
+}
+
+// validationError answers 422 with one entry per invalid field.
+func validationError(ctx *fiber.Ctx, errs validation.Errors) error {
+    return ctx.Status(422).JSON(fiber.Map{"error": "validation failed", "fields": errs})
+}
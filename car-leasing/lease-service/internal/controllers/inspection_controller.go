@@ -0,0 +1,44 @@
+package controllers
+
+import (
+    "context"
+    "errors"
+
+    "github.com/gofiber/fiber/v2"
+    "leaseCar/lease-service/internal/dtos"
+    "leaseCar/lease-service/internal/services"
+)
+
+type InspectionController struct {
+    svc *services.InspectionService
+}
+
+func NewInspectionController(s *services.InspectionService) *InspectionController {
+    return &InspectionController{svc: s}
+}
+
+func (c *InspectionController) Record(ctx *fiber.Ctx) error {
+    var in dtos.InspectionRequest
+    if err := ctx.BodyParser(&in); err != nil {
+        return ctx.Status(400).JSON(fiber.Map{"error": "invalid body"})
+    }
+    ins, err := c.svc.Record(context.Background(), ctx.Params("id"), &in)
+    if errors.Is(err, services.ErrAlreadyInspected) || errors.Is(err, services.ErrRenewalPending) {
+        return ctx.Status(409).JSON(fiber.Map{"error": err.Error()})
+    }
+    if err != nil {
+        return transitionError(ctx, err)
+    }
+    return ctx.Status(201).JSON(ins)
+}
+
+func (c *InspectionController) Get(ctx *fiber.Ctx) error {
+    ins, err := c.svc.Get(context.Background(), ctx.Params("id"))
+    if errors.Is(err, services.ErrInspectionRequired) {
+        return ctx.Status(404).JSON(fiber.Map{"error": "not found"})
+    }
+    if err != nil {
+        return internalError(ctx, err)
+    }
+    return ctx.JSON(ins)
+}
@@ -2,11 +2,16 @@ package controllers
 
 import (
     "context"
+    "errors"
+    "strings"
     "time"
 
     "github.com/gofiber/fiber/v2"
+    "github.com/jackc/pgx/v5/pgconn"
     "leaseCar/lease-service/internal/dtos"
+    "leaseCar/lease-service/internal/pricing"
     "leaseCar/lease-service/internal/services"
+    "leaseCar/lease-service/internal/validation"
 )
 
 type LeaseController struct {
@@ -30,25 +35,298 @@ func (c *LeaseController) Create(ctx *fiber.Ctx) error {
     }
     id, err := c.svc.Create(context.Background(), &in)
     if err != nil {
-        return ctx.Status(500).JSON(fiber.Map{"error": err.Error()})
+        return createError(ctx, err)
     }
     return ctx.Status(201).JSON(fiber.Map{"id": id})
 }
 
+// Quote prices candidate terms for a vehicle without creating a lease.
+func (c *LeaseController) Quote(ctx *fiber.Ctx) error {
+    var in dtos.LeaseQuoteRequest
+    if err := ctx.BodyParser(&in); err != nil {
+        return ctx.Status(400).JSON(fiber.Map{"error": "invalid body"})
+    }
+    if in.StartDate.IsZero() {
+        in.StartDate = time.Now()
+    }
+    res, err := c.svc.Quote(context.Background(), &in)
+    if err != nil {
+        return createError(ctx, err)
+    }
+    return ctx.JSON(res)
+}
+
 func (c *LeaseController) GetByID(ctx *fiber.Ctx) error {
     id := ctx.Params("id")
     l, err := c.svc.GetByID(context.Background(), id)
-    if err != nil {
+    if errors.Is(err, services.ErrLeaseNotFound) {
         return ctx.Status(404).JSON(fiber.Map{"error": "not found"})
     }
+    if err != nil {
+        return internalError(ctx, err)
+    }
     return ctx.JSON(l)
 }
 
+func (c *LeaseController) ListByUser(ctx *fiber.Ctx) error {
+    in := dtos.LeaseListRequest{
+        UserID: ctx.Params("id"),
+        Status: ctx.Query("status"),
+        Cursor: ctx.Query("cursor"),
+        Limit:  ctx.QueryInt("limit", 20),
+    }
+    page, err := c.svc.ListByUser(context.Background(), &in)
+    if errors.Is(err, services.ErrInvalidCursor) {
+        return ctx.Status(400).JSON(fiber.Map{"error": err.Error()})
+    }
+    if err != nil {
+        return internalError(ctx, err)
+    }
+    return ctx.JSON(page)
+}
+
 func (c *LeaseController) Search(ctx *fiber.Ctx) error {
-    q := ctx.Query("q", "")
-    res, err := c.svc.Search(context.Background(), q, 20)
+    in := dtos.LeaseSearchRequest{
+        Query:     ctx.Query("q", ""),
+        Status:    splitQuery(ctx.Query("status")),
+        UserID:    ctx.Query("user_id"),
+        VehicleID: ctx.Query("vehicle_id"),
+        Sort:      ctx.Query("sort"),
+        Facets:    splitQuery(ctx.Query("facets", "status")),
+        Limit:     ctx.QueryInt("limit", 20),
+        Offset:    ctx.QueryInt("offset", 0),
+    }
+    var err error
+    if in.StartFrom, err = queryDate(ctx, "start_from"); err != nil {
+        return ctx.Status(400).JSON(fiber.Map{"error": err.Error()})
+    }
+    if in.StartTo, err = queryDate(ctx, "start_to"); err != nil {
+        return ctx.Status(400).JSON(fiber.Map{"error": err.Error()})
+    }
+    if in.EndFrom, err = queryDate(ctx, "end_from"); err != nil {
+        return ctx.Status(400).JSON(fiber.Map{"error": err.Error()})
+    }
+    if in.EndTo, err = queryDate(ctx, "end_to"); err != nil {
+        return ctx.Status(400).JSON(fiber.Map{"error": err.Error()})
+    }
+    if in.MinMonthly, err = queryFloat(ctx, "min_monthly"); err != nil {
+        return ctx.Status(400).JSON(fiber.Map{"error": err.Error()})
+    }
+    if in.MaxMonthly, err = queryFloat(ctx, "max_monthly"); err != nil {
+        return ctx.Status(400).JSON(fiber.Map{"error": err.Error()})
+    }
+
+    res, err := c.svc.Search(context.Background(), &in)
+    if errors.Is(err, services.ErrInvalidSearch) {
+        return ctx.Status(400).JSON(fiber.Map{"error": err.Error()})
+    }
     if err != nil {
-        return ctx.Status(500).JSON(fiber.Map{"error": err.Error()})
+        return internalError(ctx, err)
     }
     return ctx.JSON(res)
 }
+
+func (c *LeaseController) Schedule(ctx *fiber.Ctx) error {
+    schedule, err := c.svc.GetSchedule(context.Background(), ctx.Params("id"))
+    if errors.Is(err, services.ErrLeaseNotFound) {
+        return ctx.Status(404).JSON(fiber.Map{"error": "not found"})
+    }
+    if err != nil {
+        return internalError(ctx, err)
+    }
+    return ctx.JSON(schedule)
+}
+
+func (c *LeaseController) Balance(ctx *fiber.Ctx) error {
+    balance, err := c.svc.Balance(context.Background(), ctx.Params("id"))
+    if errors.Is(err, services.ErrLeaseNotFound) {
+        return ctx.Status(404).JSON(fiber.Map{"error": "not found"})
+    }
+    if err != nil {
+        return internalError(ctx, err)
+    }
+    return ctx.JSON(balance)
+}
+
+func (c *LeaseController) Submit(ctx *fiber.Ctx) error {
+    return c.transition(ctx, services.ActionSubmit)
+}
+
+func (c *LeaseController) Approve(ctx *fiber.Ctx) error {
+    return c.transition(ctx, services.ActionApprove)
+}
+
+func (c *LeaseController) Reject(ctx *fiber.Ctx) error {
+    return c.transition(ctx, services.ActionReject)
+}
+
+func (c *LeaseController) Activate(ctx *fiber.Ctx) error {
+    return c.transition(ctx, services.ActionActivate)
+}
+
+func (c *LeaseController) Complete(ctx *fiber.Ctx) error {
+    return c.transition(ctx, services.ActionComplete)
+}
+
+func (c *LeaseController) Cancel(ctx *fiber.Ctx) error {
+    return c.transition(ctx, services.ActionCancel)
+}
+
+func (c *LeaseController) Extend(ctx *fiber.Ctx) error {
+    var in dtos.LeaseExtendRequest
+    if err := ctx.BodyParser(&in); err != nil {
+        return ctx.Status(400).JSON(fiber.Map{"error": "invalid body"})
+    }
+    ext, err := c.svc.Extend(context.Background(), ctx.Params("id"), &in)
+    if err != nil {
+        return transitionError(ctx, err)
+    }
+    return ctx.JSON(ext)
+}
+
+func (c *LeaseController) Renew(ctx *fiber.Ctx) error {
+    var in dtos.LeaseRenewRequest
+    if len(ctx.Body()) > 0 {
+        if err := ctx.BodyParser(&in); err != nil {
+            return ctx.Status(400).JSON(fiber.Map{"error": "invalid body"})
+        }
+    }
+    id, err := c.svc.Renew(context.Background(), ctx.Params("id"), &in)
+    if err != nil {
+        return transitionError(ctx, err)
+    }
+    return ctx.Status(201).JSON(fiber.Map{"id": id, "previous_lease_id": ctx.Params("id")})
+}
+
+func (c *LeaseController) Amend(ctx *fiber.Ctx) error {
+    var in dtos.LeaseAmendRequest
+    if err := ctx.BodyParser(&in); err != nil {
+        return ctx.Status(400).JSON(fiber.Map{"error": "invalid body"})
+    }
+    a, err := c.svc.Amend(context.Background(), ctx.Params("id"), &in)
+    var pgErr *pgconn.PgError
+    if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+        return validationError(ctx, validation.Errors{{Field: "actor_id", Message: "does not exist"}})
+    }
+    if err != nil {
+        return transitionError(ctx, err)
+    }
+    return ctx.Status(201).JSON(a)
+}
+
+func (c *LeaseController) History(ctx *fiber.Ctx) error {
+    h, err := c.svc.History(context.Background(), ctx.Params("id"))
+    if errors.Is(err, services.ErrLeaseNotFound) {
+        return ctx.Status(404).JSON(fiber.Map{"error": "not found"})
+    }
+    if err != nil {
+        return internalError(ctx, err)
+    }
+    return ctx.JSON(h)
+}
+
+func (c *LeaseController) CreditDecisions(ctx *fiber.Ctx) error {
+    decisions, err := c.svc.CreditDecisions(context.Background(), ctx.Params("id"))
+    if errors.Is(err, services.ErrLeaseNotFound) {
+        return ctx.Status(404).JSON(fiber.Map{"error": "not found"})
+    }
+    if err != nil {
+        return internalError(ctx, err)
+    }
+    return ctx.JSON(decisions)
+}
+
+func (c *LeaseController) TerminationQuote(ctx *fiber.Ctx) error {
+    q, err := c.svc.TerminationQuote(context.Background(), ctx.Params("id"))
+    if err != nil {
+        return transitionError(ctx, err)
+    }
+    return ctx.JSON(q)
+}
+
+func (c *LeaseController) Terminate(ctx *fiber.Ctx) error {
+    var in dtos.TerminateRequest
+    if len(ctx.Body()) > 0 {
+        if err := ctx.BodyParser(&in); err != nil {
+            return ctx.Status(400).JSON(fiber.Map{"error": "invalid body"})
+        }
+    }
+    l, err := c.svc.Terminate(context.Background(), ctx.Params("id"), &in)
+    if err != nil {
+        return transitionError(ctx, err)
+    }
+    return ctx.JSON(l)
+}
+
+func (c *LeaseController) transition(ctx *fiber.Ctx, action string) error {
+    var in dtos.LeaseTransitionRequest
+    if len(ctx.Body()) > 0 {
+        if err := ctx.BodyParser(&in); err != nil {
+            return ctx.Status(400).JSON(fiber.Map{"error": "invalid body"})
+        }
+    }
+    l, err := c.svc.Transition(context.Background(), ctx.Params("id"), action, in.Reason)
+    if err != nil {
+        return transitionError(ctx, err)
+    }
+    return ctx.JSON(l)
+}
+
+// createError maps lease creation and quote failures: invalid input and
+// references to missing users or vehicles are field errors (422), overlaps
+// are 409.
+func createError(ctx *fiber.Ctx, err error) error {
+    var fields validation.Errors
+    var conflict *services.LeaseConflictError
+    var pgErr *pgconn.PgError
+    switch {
+    case errors.As(err, &fields):
+        return validationError(ctx, fields)
+    case errors.As(err, &conflict):
+        return ctx.Status(409).JSON(fiber.Map{"error": err.Error(), "conflicting_lease_id": conflict.LeaseID})
+    case errors.Is(err, services.ErrVehicleNotFound):
+        return validationError(ctx, validation.Errors{{Field: "vehicle_id", Message: "does not exist"}})
+    case errors.Is(err, services.ErrVehicleHeld):
+        return ctx.Status(409).JSON(fiber.Map{"error": err.Error()})
+    case errors.Is(err, pricing.ErrInvalidTerm):
+        return validationError(ctx, validation.Errors{{Field: "end_date", Message: err.Error()}})
+    case errors.Is(err, pricing.ErrInvalidDeposit):
+        return validationError(ctx, validation.Errors{{Field: "deposit_paid", Message: err.Error()}})
+    case errors.As(err, &pgErr) && pgErr.Code == "23503":
+        field := "vehicle_id"
+        if strings.Contains(pgErr.ConstraintName, "user_id") {
+            field = "user_id"
+        }
+        return validationError(ctx, validation.Errors{{Field: field, Message: "does not exist"}})
+    }
+    return internalError(ctx, err)
+}
+
+func transitionError(ctx *fiber.Ctx, err error) error {
+    var invalid *services.InvalidTransitionError
+    var conflict *services.LeaseConflictError
+    var fields validation.Errors
+    switch {
+    case errors.As(err, &fields):
+        return validationError(ctx, fields)
+    case errors.As(err, &conflict):
+        return ctx.Status(409).JSON(fiber.Map{"error": err.Error(), "conflicting_lease_id": conflict.LeaseID})
+    case errors.Is(err, services.ErrAlreadyRenewed), errors.Is(err, services.ErrDepositSettled):
+        return ctx.Status(409).JSON(fiber.Map{"error": err.Error()})
+    case errors.Is(err, services.ErrInvalidExtension), errors.Is(err, pricing.ErrInvalidTerm), errors.Is(err, pricing.ErrInvalidDeposit):
+        return ctx.Status(400).JSON(fiber.Map{"error": err.Error()})
+    case errors.Is(err, services.ErrLeaseNotFound):
+        return ctx.Status(404).JSON(fiber.Map{"error": "not found"})
+    case errors.As(err, &conflict):
+        return ctx.Status(409).JSON(fiber.Map{"error": err.Error(), "conflicting_lease_id": conflict.LeaseID})
+    case errors.As(err, &invalid), errors.Is(err, services.ErrLeaseStatusChanged):
+        return ctx.Status(409).JSON(fiber.Map{"error": err.Error()})
+    case errors.Is(err, services.ErrInspectionRequired):
+        return ctx.Status(409).JSON(fiber.Map{"error": err.Error()})
+    case errors.Is(err, services.ErrQuoteRequired):
+        return ctx.Status(400).JSON(fiber.Map{"error": err.Error()})
+    case errors.Is(err, services.ErrQuoteNotFound):
+        return ctx.Status(422).JSON(fiber.Map{"error": err.Error()})
+    }
+    return internalError(ctx, err)
+}
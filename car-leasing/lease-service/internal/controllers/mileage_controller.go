@@ -0,0 +1,51 @@
+package controllers
+
+import (
+    "context"
+    "errors"
+
+    "github.com/gofiber/fiber/v2"
+    "leaseCar/lease-service/internal/dtos"
+    "leaseCar/lease-service/internal/services"
+)
+
+type MileageController struct {
+    svc *services.MileageService
+}
+
+func NewMileageController(s *services.MileageService) *MileageController {
+    return &MileageController{svc: s}
+}
+
+func (c *MileageController) RecordReading(ctx *fiber.Ctx) error {
+    var in dtos.OdometerReadingRequest
+    if err := ctx.BodyParser(&in); err != nil {
+        return ctx.Status(400).JSON(fiber.Map{"error": "invalid body"})
+    }
+    report, err := c.svc.RecordReading(context.Background(), ctx.Params("id"), &in)
+    if err != nil {
+        return mileageError(ctx, err)
+    }
+    return ctx.Status(201).JSON(report)
+}
+
+func (c *MileageController) Report(ctx *fiber.Ctx) error {
+    report, err := c.svc.Report(context.Background(), ctx.Params("id"))
+    if err != nil {
+        return mileageError(ctx, err)
+    }
+    return ctx.JSON(report)
+}
+
+func mileageError(ctx *fiber.Ctx, err error) error {
+    var invalid *services.InvalidTransitionError
+    switch {
+    case errors.Is(err, services.ErrLeaseNotFound):
+        return ctx.Status(404).JSON(fiber.Map{"error": "not found"})
+    case errors.Is(err, services.ErrInvalidReading):
+        return ctx.Status(400).JSON(fiber.Map{"error": err.Error()})
+    case errors.As(err, &invalid), errors.Is(err, services.ErrReadingDecreasing):
+        return ctx.Status(409).JSON(fiber.Map{"error": err.Error()})
+    }
+    return internalError(ctx, err)
+}
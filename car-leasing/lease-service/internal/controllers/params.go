@@ -0,0 +1,17 @@
+package controllers
+
+import (
+    "github.com/gofiber/fiber/v2"
+    "leaseCar/lease-service/internal/validation"
+)
+
+// UUIDParam rejects a request with 400 unless the named route parameter is
+// a UUID, so malformed ids never reach a handler or the database.
+func UUIDParam(name string) fiber.Handler {
+    return func(ctx *fiber.Ctx) error {
+        if !validation.IsUUID(ctx.Params(name)) {
+            return ctx.Status(400).JSON(fiber.Map{"error": name + " must be a UUID"})
+        }
+        return ctx.Next()
+    }
+}
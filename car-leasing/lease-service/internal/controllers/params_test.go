@@ -0,0 +1,34 @@
+package controllers
+
+import (
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gofiber/fiber/v2"
+)
+
+func TestUUIDParam(t *testing.T) {
+    app := fiber.New()
+    app.Get("/leases/:id", UUIDParam("id"), func(ctx *fiber.Ctx) error {
+        return ctx.SendStatus(204)
+    })
+    tests := []struct {
+        id   string
+        want int
+    }{
+        {"3f2b7c1e-9a4d-4e6f-8b1a-2c3d4e5f6a7b", 204},
+        {"3F2B7C1E-9A4D-4E6F-8B1A-2C3D4E5F6A7B", 204},
+        {"42", 400},
+        {"3f2b7c1e-9a4d-4e6f-8b1a-2c3d4e5f6a7", 400},
+        {"3f2b7c1e9a4d4e6f8b1a2c3d4e5f6a7b", 400},
+    }
+    for _, tt := range tests {
+        res, err := app.Test(httptest.NewRequest("GET", "/leases/"+tt.id, nil))
+        if err != nil {
+            t.Fatal(err)
+        }
+        if res.StatusCode != tt.want {
+            t.Errorf("GET /leases/%s = %d, want %d", tt.id, res.StatusCode, tt.want)
+        }
+    }
+}
@@ -0,0 +1,47 @@
+package controllers
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/gofiber/fiber/v2"
+)
+
+// splitQuery splits a comma-separated query value, dropping empty items.
+func splitQuery(v string) []string {
+    var out []string
+    for _, part := range strings.Split(v, ",") {
+        if part = strings.TrimSpace(part); part != "" {
+            out = append(out, part)
+        }
+    }
+    return out
+}
+
+// queryDate parses an optional YYYY-MM-DD query parameter.
+func queryDate(ctx *fiber.Ctx, key string) (*time.Time, error) {
+    v := ctx.Query(key)
+    if v == "" {
+        return nil, nil
+    }
+    t, err := time.Parse("2006-01-02", v)
+    if err != nil {
+        return nil, fmt.Errorf("%s must be a date (YYYY-MM-DD)", key)
+    }
+    return &t, nil
+}
+
+// queryFloat parses an optional numeric query parameter.
+func queryFloat(ctx *fiber.Ctx, key string) (*float64, error) {
+    v := ctx.Query(key)
+    if v == "" {
+        return nil, nil
+    }
+    f, err := strconv.ParseFloat(v, 64)
+    if err != nil {
+        return nil, fmt.Errorf("%s must be a number", key)
+    }
+    return &f, nil
+}
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+    "context"
+    "errors"
+
+    "github.com/gofiber/fiber/v2"
+    "leaseCar/lease-service/internal/dtos"
+    "leaseCar/lease-service/internal/services"
+)
+
+type ReservationController struct {
+    svc *services.ReservationService
+}
+
+func NewReservationController(s *services.ReservationService) *ReservationController {
+    return &ReservationController{svc: s}
+}
+
+func (c *ReservationController) Hold(ctx *fiber.Ctx) error {
+    var in dtos.VehicleHoldRequest
+    if err := ctx.BodyParser(&in); err != nil {
+        return ctx.Status(400).JSON(fiber.Map{"error": "invalid body"})
+    }
+    hold, err := c.svc.Hold(context.Background(), ctx.Params("id"), &in)
+    if err != nil {
+        return reservationError(ctx, err)
+    }
+    return ctx.Status(201).JSON(hold)
+}
+
+func (c *ReservationController) Get(ctx *fiber.Ctx) error {
+    hold, err := c.svc.Get(context.Background(), ctx.Params("id"))
+    if err != nil {
+        return internalError(ctx, err)
+    }
+    if hold == nil {
+        return ctx.Status(404).JSON(fiber.Map{"error": "not found"})
+    }
+    return ctx.JSON(hold)
+}
+
+func (c *ReservationController) Release(ctx *fiber.Ctx) error {
+    if err := c.svc.Release(context.Background(), ctx.Params("id"), ctx.Query("user_id")); err != nil {
+        return reservationError(ctx, err)
+    }
+    return ctx.SendStatus(204)
+}
+
+func reservationError(ctx *fiber.Ctx, err error) error {
+    var conflict *services.LeaseConflictError
+    switch {
+    case errors.As(err, &conflict):
+        return ctx.Status(409).JSON(fiber.Map{"error": err.Error(), "conflicting_lease_id": conflict.LeaseID})
+    case errors.Is(err, services.ErrVehicleNotFound), errors.Is(err, services.ErrHoldNotFound):
+        return ctx.Status(404).JSON(fiber.Map{"error": err.Error()})
+    case errors.Is(err, services.ErrInvalidHold):
+        return ctx.Status(400).JSON(fiber.Map{"error": err.Error()})
+    case errors.Is(err, services.ErrVehicleHeld), errors.Is(err, services.ErrVehicleUnavailable):
+        return ctx.Status(409).JSON(fiber.Map{"error": err.Error()})
+    }
+    return internalError(ctx, err)
+}
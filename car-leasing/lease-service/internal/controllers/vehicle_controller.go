@@ -0,0 +1,91 @@
+package controllers
+
+import (
+    "context"
+    "errors"
+
+    "github.com/gofiber/fiber/v2"
+    "github.com/jackc/pgx/v5/pgconn"
+    "leaseCar/lease-service/internal/dtos"
+    "leaseCar/lease-service/internal/services"
+)
+
+type VehicleController struct {
+    svc *services.VehicleService
+}
+
+func NewVehicleController(s *services.VehicleService) *VehicleController {
+    return &VehicleController{svc: s}
+}
+
+func (c *VehicleController) Create(ctx *fiber.Ctx) error {
+    var in dtos.VehicleCreateRequest
+    if err := ctx.BodyParser(&in); err != nil {
+        return ctx.Status(400).JSON(fiber.Map{"error": "invalid body"})
+    }
+    v, err := c.svc.Create(context.Background(), &in)
+    if err != nil {
+        return vehicleError(ctx, err)
+    }
+    return ctx.Status(201).JSON(v)
+}
+
+func (c *VehicleController) GetByID(ctx *fiber.Ctx) error {
+    v, err := c.svc.GetByID(context.Background(), ctx.Params("id"), ctx.Query("user_id"))
+    if err != nil {
+        return vehicleError(ctx, err)
+    }
+    return ctx.JSON(v)
+}
+
+func (c *VehicleController) List(ctx *fiber.Ctx) error {
+    f := dtos.VehicleFilter{
+        VehicleType: ctx.Query("type"),
+        ViewerID:    ctx.Query("user_id"),
+        Limit:       ctx.QueryInt("limit", 20),
+        Offset:      ctx.QueryInt("offset", 0),
+    }
+    if ctx.Query("available") != "" {
+        available := ctx.QueryBool("available")
+        f.Available = &available
+    }
+    vehicles, err := c.svc.List(context.Background(), f)
+    if err != nil {
+        return vehicleError(ctx, err)
+    }
+    return ctx.JSON(vehicles)
+}
+
+func (c *VehicleController) Update(ctx *fiber.Ctx) error {
+    var in dtos.VehicleUpdateRequest
+    if err := ctx.BodyParser(&in); err != nil {
+        return ctx.Status(400).JSON(fiber.Map{"error": "invalid body"})
+    }
+    v, err := c.svc.Update(context.Background(), ctx.Params("id"), &in)
+    if err != nil {
+        return vehicleError(ctx, err)
+    }
+    return ctx.JSON(v)
+}
+
+func (c *VehicleController) Delete(ctx *fiber.Ctx) error {
+    if err := c.svc.Delete(context.Background(), ctx.Params("id")); err != nil {
+        return vehicleError(ctx, err)
+    }
+    return ctx.SendStatus(204)
+}
+
+func vehicleError(ctx *fiber.Ctx, err error) error {
+    var pgErr *pgconn.PgError
+    switch {
+    case errors.Is(err, services.ErrVehicleNotFound):
+        return ctx.Status(404).JSON(fiber.Map{"error": "not found"})
+    case errors.Is(err, services.ErrInvalidVIN), errors.Is(err, services.ErrInvalidVehicleType), errors.Is(err, services.ErrInvalidVehicle):
+        return ctx.Status(400).JSON(fiber.Map{"error": err.Error()})
+    case errors.As(err, &pgErr) && pgErr.Code == "23505":
+        return ctx.Status(409).JSON(fiber.Map{"error": "vehicle with this vin or license plate already exists"})
+    case errors.As(err, &pgErr) && pgErr.Code == "23503":
+        return ctx.Status(409).JSON(fiber.Map{"error": "vehicle is referenced by a lease"})
+    }
+    return internalError(ctx, err)
+}
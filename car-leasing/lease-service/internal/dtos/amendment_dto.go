@@ -0,0 +1,56 @@
+package dtos
+
+import "time"
+
+// LeaseAmendRequest changes a lease's terms. Nil fields are left unchanged;
+// at least one must be set.
+type LeaseAmendRequest struct {
+    ActorID        string    `json:"actor_id"`
+    Reason         string    `json:"reason"`
+    EffectiveDate  time.Time `json:"effective_date"`
+    MonthlyPayment *float64  `json:"monthly_payment"`
+    MileageLimit   *int      `json:"mileage_limit"`
+    Notes          *string   `json:"notes"`
+}
+
+type FieldChange struct {
+    Before interface{} `json:"before"`
+    After  interface{} `json:"after"`
+}
+
+// LeaseAmendment is one numbered version of a lease's terms.
+type LeaseAmendment struct {
+    ID            string                 `json:"id"`
+    LeaseID       string                 `json:"lease_id"`
+    Version       int                    `json:"version"`
+    ActorID       *string                `json:"actor_id,omitempty"`
+    Reason        string                 `json:"reason"`
+    EffectiveDate time.Time              `json:"effective_date"`
+    Changes       map[string]FieldChange `json:"changes"`
+    CreatedAt     time.Time              `json:"created_at"`
+}
+
+// History entry types.
+const (
+    LeaseHistoryCreated  = "created"
+    LeaseHistoryApproved = "approved"
+    LeaseHistoryStarted  = "started"
+    LeaseHistoryEnded    = "ended"
+    LeaseHistoryExtended = "extended"
+    LeaseHistoryAmended  = "amended"
+)
+
+type LeaseHistoryEntry struct {
+    Type          string                 `json:"type"`
+    At            time.Time              `json:"at"`
+    Version       int                    `json:"version,omitempty"`
+    ActorID       *string                `json:"actor_id,omitempty"`
+    Reason        string                 `json:"reason,omitempty"`
+    EffectiveDate *time.Time             `json:"effective_date,omitempty"`
+    Changes       map[string]FieldChange `json:"changes,omitempty"`
+}
+
+type LeaseHistory struct {
+    LeaseID string              `json:"lease_id"`
+    Entries []LeaseHistoryEntry `json:"entries"`
+}
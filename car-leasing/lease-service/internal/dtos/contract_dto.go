@@ -0,0 +1,22 @@
+package dtos
+
+import "time"
+
+// ContractParty is the lessee as named on the contract.
+type ContractParty struct {
+    UserID string `json:"user_id"`
+    Name   string `json:"name"`
+    Email  string `json:"email"`
+    Phone  string `json:"phone,omitempty"`
+}
+
+// LeaseContract records the SHA-256 of a generated contract PDF together
+// with the lease version (updated_at) it was rendered from.
+type LeaseContract struct {
+    ID             string    `json:"id"`
+    LeaseID        string    `json:"lease_id"`
+    SHA256         string    `json:"sha256"`
+    SizeBytes      int       `json:"size_bytes"`
+    LeaseUpdatedAt time.Time `json:"lease_updated_at"`
+    GeneratedAt    time.Time `json:"generated_at"`
+}
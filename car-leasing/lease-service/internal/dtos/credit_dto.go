@@ -0,0 +1,37 @@
+package dtos
+
+import "time"
+
+// Credit decision outcomes. REVIEW leaves the lease in PENDING_APPROVAL for
+// a person to approve or reject.
+const (
+    CreditApprove = "APPROVE"
+    CreditReject  = "REJECT"
+    CreditReview  = "REVIEW"
+)
+
+// CreditReport is what a credit bureau knows about an applicant.
+type CreditReport struct {
+    UserID        string  `json:"user_id"`
+    Score         int     `json:"score"`
+    MonthlyIncome float64 `json:"monthly_income"`
+    Source        string  `json:"source"`
+}
+
+// CreditReason explains one rule's contribution to a decision.
+type CreditReason struct {
+    Rule    string `json:"rule"`
+    Outcome string `json:"outcome"`
+    Message string `json:"message"`
+}
+
+type CreditDecision struct {
+    ID         string                 `json:"id"`
+    LeaseID    string                 `json:"lease_id"`
+    Outcome    string                 `json:"outcome"`
+    Decisioner string                 `json:"decisioner"`
+    Score      *int                   `json:"score,omitempty"`
+    Reasons    []CreditReason         `json:"reasons"`
+    Inputs     map[string]interface{} `json:"inputs"`
+    CreatedAt  time.Time              `json:"created_at"`
+}
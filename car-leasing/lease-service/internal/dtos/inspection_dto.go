@@ -0,0 +1,57 @@
+package dtos
+
+import "time"
+
+// Damage severities. Any SEVERE item takes the vehicle out of service until
+// it is repaired.
+const (
+    DamageMinor    = "MINOR"
+    DamageModerate = "MODERATE"
+    DamageSevere   = "SEVERE"
+)
+
+// InspectionChecklist lists the items every return inspection must cover.
+var InspectionChecklist = []string{"exterior", "interior", "tires", "lights", "windshield", "keys", "documents"}
+
+type InspectionChecklistItem struct {
+    Item   string `json:"item"`
+    Passed bool   `json:"passed"`
+    Notes  string `json:"notes,omitempty"`
+}
+
+type InspectionDamage struct {
+    ID          string   `json:"id,omitempty"`
+    Area        string   `json:"area"`
+    Description string   `json:"description"`
+    Severity    string   `json:"severity"`
+    RepairCost  float64  `json:"repair_cost"`
+    Photos      []string `json:"photos,omitempty"`
+}
+
+type InspectionRequest struct {
+    InspectorID string                    `json:"inspector_id"`
+    Odometer    int                       `json:"odometer"`
+    Checklist   []InspectionChecklistItem `json:"checklist"`
+    Damages     []InspectionDamage        `json:"damages"`
+    Photos      []string                  `json:"photos"`
+    Notes       string                    `json:"notes"`
+}
+
+// LeaseInspection is a recorded return inspection and its deposit
+// settlement.
+type LeaseInspection struct {
+    ID             string                    `json:"id"`
+    LeaseID        string                    `json:"lease_id"`
+    InspectorID    *string                   `json:"inspector_id,omitempty"`
+    Odometer       int                       `json:"odometer"`
+    Checklist      []InspectionChecklistItem `json:"checklist"`
+    Damages        []InspectionDamage        `json:"damages"`
+    Photos         []string                  `json:"photos"`
+    Notes          string                    `json:"notes,omitempty"`
+    DamageTotal    float64                   `json:"damage_total"`
+    DepositApplied float64                   `json:"deposit_applied"`
+    AmountDue      float64                   `json:"amount_due"`
+    DepositRefund  float64                   `json:"deposit_refund"`
+    RequiresRepair bool                      `json:"requires_repair"`
+    InspectedAt    time.Time                 `json:"inspected_at"`
+}
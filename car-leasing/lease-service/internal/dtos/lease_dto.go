@@ -1,6 +1,22 @@
 package dtos
 
-import "time"
+import (
+    "time"
+
+    "leaseCar/lease-service/internal/validation"
+)
+
+// Lease statuses, mirroring the lease_status enum in Postgres.
+const (
+    LeaseStatusDraft           = "DRAFT"
+    LeaseStatusPendingApproval = "PENDING_APPROVAL"
+    LeaseStatusApproved        = "APPROVED"
+    LeaseStatusActive          = "ACTIVE"
+    LeaseStatusRejected        = "REJECTED"
+    LeaseStatusCompleted       = "COMPLETED"
+    LeaseStatusTerminated      = "TERMINATED"
+    LeaseStatusCancelled       = "CANCELLED"
+)
 
 type LeaseCreateRequest struct {
     UserID     string    `json:"user_id"`
@@ -8,8 +24,41 @@ type LeaseCreateRequest struct {
     StartDate  time.Time `json:"start_date"`
     EndDate    time.Time `json:"end_date"`
     Monthly    float64   `json:"monthly_payment"`
-    Deposit    float64   `json:"deposit_paid"`
+    // Deposit is the deposit the lessee pays up front; nil uses the
+    // vehicle's deposit_amount and 0 asks for no deposit.
+    Deposit    *float64  `json:"deposit_paid"`
     MileageLimit int     `json:"mileage_limit"`
+    PreviousLeaseID string `json:"-"` // set by renewals only
+}
+
+// Validate checks the request before it reaches pricing or the database.
+// Monthly payment and total cost are computed server-side, but a negative
+// client value is still rejected rather than silently replaced.
+func (r *LeaseCreateRequest) Validate() error {
+    var errs validation.Errors
+    if r.UserID == "" {
+        errs.Add("user_id", "is required")
+    } else if !validation.IsUUID(r.UserID) {
+        errs.Add("user_id", "must be a UUID")
+    }
+    if r.VehicleID == "" {
+        errs.Add("vehicle_id", "is required")
+    } else if !validation.IsUUID(r.VehicleID) {
+        errs.Add("vehicle_id", "must be a UUID")
+    }
+    if !r.EndDate.After(r.StartDate) {
+        errs.Add("end_date", "must be after start_date")
+    }
+    if r.Monthly < 0 {
+        errs.Add("monthly_payment", "must not be negative")
+    }
+    if r.Deposit != nil && *r.Deposit < 0 {
+        errs.Add("deposit_paid", "must not be negative")
+    }
+    if r.MileageLimit <= 0 {
+        errs.Add("mileage_limit", "must be greater than zero")
+    }
+    return errs.Err()
 }
 
 type Lease struct {
@@ -21,6 +70,148 @@ type Lease struct {
     EndDate    time.Time `json:"end_date"`
     Monthly    float64   `json:"monthly_payment"`
     Deposit    float64   `json:"deposit_paid"`
+    DepositTransferred float64 `json:"deposit_transferred"` // carried over to a renewal
     TotalCost  float64   `json:"total_cost"`
+    MileageLimit int     `json:"mileage_limit"`
     CreatedAt  time.Time `json:"created_at"`
+    UpdatedAt  time.Time `json:"updated_at"`
+    ApprovedAt *time.Time `json:"approved_at,omitempty"`
+    StartedAt  *time.Time `json:"started_at,omitempty"`
+    EndedAt    *time.Time `json:"ended_at,omitempty"`
+    Pricing    *LeasePricing `json:"pricing,omitempty"`
+    PreviousLeaseID *string `json:"previous_lease_id,omitempty"`
+    Notes      string    `json:"notes,omitempty"`
+}
+
+// DepositHeld is the part of the deposit still held against this lease, i.e.
+// what was not carried over to a renewal.
+func (l *Lease) DepositHeld() float64 {
+    return l.Deposit - l.DepositTransferred
+}
+
+// LeasePricing is the server-side price breakdown stored with each lease in
+// leases.pricing_breakdown.
+type LeasePricing struct {
+    TermMonths          int     `json:"term_months"`
+    PricePerMonth       float64 `json:"price_per_month"`
+    GrossCapCost        float64 `json:"gross_cap_cost"`
+    Fees                float64 `json:"fees"`
+    Deposit             float64 `json:"deposit"`
+    AdjustedCapCost     float64 `json:"adjusted_cap_cost"`
+    ResidualValue       float64 `json:"residual_value"`
+    MoneyFactor         float64 `json:"money_factor"`
+    DepreciationMonthly float64 `json:"depreciation_monthly"`
+    FinanceMonthly      float64 `json:"finance_monthly"`
+    MonthlyPayment      float64 `json:"monthly_payment"`
+    TotalCost           float64 `json:"total_cost"`
+}
+
+// LeaseDocument is the shape of a lease in the Meilisearch "leases" index.
+// Dates are duplicated as unix timestamps for range filters and sorting.
+type LeaseDocument struct {
+    ID           string    `json:"id"`
+    UserID       string    `json:"user_id"`
+    VehicleID    string    `json:"vehicle_id"`
+    Status       string    `json:"status"`
+    StartDate    time.Time `json:"start_date"`
+    EndDate      time.Time `json:"end_date"`
+    Monthly      float64   `json:"monthly_payment"`
+    Deposit      float64   `json:"deposit_paid"`
+    TotalCost    float64   `json:"total_cost"`
+    MileageLimit int       `json:"mileage_limit"`
+    CreatedAt    time.Time `json:"created_at"`
+    StartDateTS  int64     `json:"start_date_ts"`
+    EndDateTS    int64     `json:"end_date_ts"`
+    CreatedAtTS  int64     `json:"created_at_ts"`
+}
+
+func NewLeaseDocument(l *Lease) LeaseDocument {
+    return LeaseDocument{
+        ID:           l.ID,
+        UserID:       l.UserID,
+        VehicleID:    l.VehicleID,
+        Status:       l.Status,
+        StartDate:    l.StartDate,
+        EndDate:      l.EndDate,
+        Monthly:      l.Monthly,
+        Deposit:      l.Deposit,
+        TotalCost:    l.TotalCost,
+        MileageLimit: l.MileageLimit,
+        CreatedAt:    l.CreatedAt,
+        StartDateTS:  l.StartDate.Unix(),
+        EndDateTS:    l.EndDate.Unix(),
+        CreatedAtTS:  l.CreatedAt.Unix(),
+    }
+}
+
+type LeaseTransitionRequest struct {
+    Reason string `json:"reason"`
+}
+
+type LeaseStatusChangedEvent struct {
+    Event     string    `json:"event"`
+    LeaseID   string    `json:"lease_id"`
+    From      string    `json:"from"`
+    To        string    `json:"to"`
+    Reason    string    `json:"reason,omitempty"`
+    ChangedAt time.Time `json:"changed_at"`
+}
+
+type LeaseExtendRequest struct {
+    EndDate time.Time `json:"end_date"`
+    Reason  string    `json:"reason"`
+}
+
+type LeaseExtension struct {
+    ID                string    `json:"id"`
+    LeaseID           string    `json:"lease_id"`
+    PreviousEndDate   time.Time `json:"previous_end_date"`
+    NewEndDate        time.Time `json:"new_end_date"`
+    AddedInstallments int       `json:"added_installments"`
+    AddedAmount       float64   `json:"added_amount"`
+    Reason            string    `json:"reason,omitempty"`
+    CreatedAt         time.Time `json:"created_at"`
+}
+
+type LeaseRenewRequest struct {
+    EndDate      time.Time `json:"end_date"`
+    MileageLimit int       `json:"mileage_limit"`
+}
+
+type LeaseListRequest struct {
+    UserID string
+    Status string
+    Cursor string
+    Limit  int
+}
+
+type LeasePage struct {
+    Leases     []Lease `json:"leases"`
+    NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+type LeaseSearchRequest struct {
+    Query      string
+    Status     []string
+    UserID     string
+    VehicleID  string
+    StartFrom  *time.Time
+    StartTo    *time.Time
+    EndFrom    *time.Time
+    EndTo      *time.Time
+    MinMonthly *float64
+    MaxMonthly *float64
+    Sort       string
+    Facets     []string
+    Limit      int
+    Offset     int
+}
+
+type LeaseSearchResponse struct {
+    Hits       []interface{} `json:"hits"`
+    Total      int64         `json:"total"`
+    Limit      int           `json:"limit"`
+    Offset     int           `json:"offset"`
+    NextOffset *int          `json:"next_offset,omitempty"`
+    Facets     interface{}   `json:"facets,omitempty"`
 }
@@ -0,0 +1,74 @@
+package dtos
+
+import "time"
+
+// Installment statuses stored in lease_payments.status.
+const (
+    LeasePaymentPending   = "PENDING"
+    LeasePaymentPaid      = "PAID"
+    LeasePaymentOverdue   = "OVERDUE"
+    LeasePaymentCancelled = "CANCELLED"
+)
+
+// Kinds of billable items stored in lease_payments.kind.
+const (
+    LeasePaymentInstallment    = "INSTALLMENT"
+    LeasePaymentMileageOverage = "MILEAGE_OVERAGE"
+    LeasePaymentLateFee        = "LATE_FEE"
+    LeasePaymentDamage         = "DAMAGE"
+)
+
+type LeasePayment struct {
+    ID            string     `json:"id"`
+    LeaseID       string     `json:"lease_id"`
+    PaymentNumber int        `json:"payment_number"`
+    DueDate       time.Time  `json:"due_date"`
+    Amount        float64    `json:"amount"`
+    PaidAmount    float64    `json:"paid_amount"`
+    PaidAt        *time.Time `json:"paid_at,omitempty"`
+    Status        string     `json:"status"`
+    Kind          string     `json:"kind"`
+    Description   string     `json:"description,omitempty"`
+    // RelatedPaymentID links a late fee to the overdue item it penalizes.
+    RelatedPaymentID *string `json:"related_payment_id,omitempty"`
+}
+
+// LeaseBalance summarizes what a lease owes as of a date. AmountDue covers
+// every unpaid item due by then, late fees included; UpcomingAmount is what
+// is still scheduled after it. Both follow lease_payments, which
+// payment-service settles when a payment completes.
+type LeaseBalance struct {
+    LeaseID        string         `json:"lease_id"`
+    AsOf           time.Time      `json:"as_of"`
+    AmountDue      float64        `json:"amount_due"`
+    OverdueAmount  float64        `json:"overdue_amount"`
+    LateFees       float64        `json:"late_fees"`
+    UpcomingAmount float64        `json:"upcoming_amount"`
+    Items          []LeasePayment `json:"items"`
+}
+
+// LeasePaymentOverdueEvent is published on the "leases" channel when an
+// unpaid item passes its due date.
+type LeasePaymentOverdueEvent struct {
+    Event          string    `json:"event"`
+    LeaseID        string    `json:"lease_id"`
+    LeasePaymentID string    `json:"lease_payment_id"`
+    PaymentNumber  int       `json:"payment_number"`
+    Kind           string    `json:"kind"`
+    DueDate        time.Time `json:"due_date"`
+    Amount         float64   `json:"amount"`
+    MarkedAt       time.Time `json:"marked_at"`
+}
+
+// LateFeeAssessedEvent is published on the "leases" channel when a late fee
+// is created or accrues. LeasePaymentID is the fee item payment-service
+// collects; RelatedPaymentID is the overdue item it penalizes.
+type LateFeeAssessedEvent struct {
+    Event            string    `json:"event"`
+    LeaseID          string    `json:"lease_id"`
+    LeasePaymentID   string    `json:"lease_payment_id"`
+    RelatedPaymentID string    `json:"related_payment_id"`
+    Amount           float64   `json:"amount"`
+    DaysLate         int       `json:"days_late"`
+    AssessedAt       time.Time `json:"assessed_at"`
+}
@@ -0,0 +1,39 @@
+package dtos
+
+import "time"
+
+type OdometerReadingRequest struct {
+    Reading    int       `json:"reading"`
+    RecordedAt time.Time `json:"recorded_at"`
+}
+
+type OdometerReading struct {
+    ID         string    `json:"id"`
+    LeaseID    string    `json:"lease_id"`
+    Reading    int       `json:"reading"`
+    RecordedAt time.Time `json:"recorded_at"`
+}
+
+// MileageReport projects a lease's mileage from its odometer readings. The
+// first reading is the baseline; projections need at least two readings a
+// day or more apart.
+type MileageReport struct {
+    LeaseID             string  `json:"lease_id"`
+    MileageLimit        int     `json:"mileage_limit"`
+    Readings            int     `json:"readings"`
+    BaselineReading     int     `json:"baseline_reading"`
+    LatestReading       int     `json:"latest_reading"`
+    MilesDriven         int     `json:"miles_driven"`
+    AnnualizedMiles     float64 `json:"annualized_miles"`
+    AnnualAllowance     float64 `json:"annual_allowance"`
+    ProjectedTotalMiles float64 `json:"projected_total_miles"`
+    ProjectedOverage    float64 `json:"projected_overage"`
+    OnTrackToExceed     bool    `json:"on_track_to_exceed"`
+}
+
+type MileageWarningEvent struct {
+    Event               string  `json:"event"`
+    LeaseID             string  `json:"lease_id"`
+    MileageLimit        int     `json:"mileage_limit"`
+    ProjectedTotalMiles float64 `json:"projected_total_miles"`
+}
@@ -0,0 +1,73 @@
+package dtos
+
+import (
+    "fmt"
+    "time"
+
+    "leaseCar/lease-service/internal/validation"
+)
+
+// MaxQuoteOptions caps how many term/deposit combinations one quote request
+// may price.
+const MaxQuoteOptions = 10
+
+type LeaseQuoteRequest struct {
+    VehicleID string             `json:"vehicle_id"`
+    StartDate time.Time          `json:"start_date"`
+    Options   []LeaseQuoteOption `json:"options"`
+}
+
+// LeaseQuoteOption is one candidate term. Either TermMonths or EndDate is
+// set; as on lease creation, an omitted deposit falls back to the vehicle's
+// deposit_amount and 0 means no deposit.
+type LeaseQuoteOption struct {
+    TermMonths int       `json:"term_months,omitempty"`
+    EndDate    time.Time `json:"end_date,omitempty"`
+    Deposit    *float64  `json:"deposit_paid"`
+}
+
+func (r *LeaseQuoteRequest) Validate() error {
+    var errs validation.Errors
+    if r.VehicleID == "" {
+        errs.Add("vehicle_id", "is required")
+    } else if !validation.IsUUID(r.VehicleID) {
+        errs.Add("vehicle_id", "must be a UUID")
+    }
+    if len(r.Options) == 0 {
+        errs.Add("options", "at least one option is required")
+    }
+    if len(r.Options) > MaxQuoteOptions {
+        errs.Add("options", fmt.Sprintf("at most %d options are allowed", MaxQuoteOptions))
+    }
+    for i, o := range r.Options {
+        field := fmt.Sprintf("options[%d].", i)
+        switch {
+        case o.TermMonths == 0 && o.EndDate.IsZero():
+            errs.Add(field+"term_months", "term_months or end_date is required")
+        case o.TermMonths != 0 && !o.EndDate.IsZero():
+            errs.Add(field+"term_months", "set either term_months or end_date, not both")
+        case o.TermMonths < 0:
+            errs.Add(field+"term_months", "must be greater than zero")
+        case !o.EndDate.IsZero() && !o.EndDate.After(r.StartDate):
+            errs.Add(field+"end_date", "must be after start_date")
+        }
+        if o.Deposit != nil && *o.Deposit < 0 {
+            errs.Add(field+"deposit_paid", "must not be negative")
+        }
+    }
+    return errs.Err()
+}
+
+// LeaseQuote prices a single option exactly as lease creation would; the
+// schedule is what activation will generate for the same dates.
+type LeaseQuote struct {
+    StartDate time.Time      `json:"start_date"`
+    EndDate   time.Time      `json:"end_date"`
+    Pricing   *LeasePricing  `json:"pricing"`
+    Schedule  []LeasePayment `json:"schedule"`
+}
+
+type LeaseQuoteResponse struct {
+    VehicleID string       `json:"vehicle_id"`
+    Quotes    []LeaseQuote `json:"quotes"`
+}
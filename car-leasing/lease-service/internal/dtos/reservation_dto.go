@@ -0,0 +1,21 @@
+package dtos
+
+import "time"
+
+// VehicleHoldRequest holds a vehicle for the period the user intends to
+// lease it; zero dates default as on lease creation.
+type VehicleHoldRequest struct {
+    UserID     string    `json:"user_id"`
+    TTLSeconds int       `json:"ttl_seconds"`
+    StartDate  time.Time `json:"start_date"`
+    EndDate    time.Time `json:"end_date"`
+}
+
+// VehicleHold is a time-limited reservation of a vehicle for one user,
+// kept in Redis and dropped automatically when it expires.
+type VehicleHold struct {
+    VehicleID string    `json:"vehicle_id"`
+    UserID    string    `json:"user_id"`
+    CreatedAt time.Time `json:"created_at"`
+    ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,25 @@
+package dtos
+
+import "time"
+
+type TerminationQuote struct {
+    ID                    string    `json:"id"`
+    LeaseID               string    `json:"lease_id"`
+    RemainingInstallments int       `json:"remaining_installments"`
+    RemainingAmount       float64   `json:"remaining_amount"`
+    EarlyTerminationFee   float64   `json:"early_termination_fee"`
+    UnpaidInstallments    int       `json:"unpaid_installments"`
+    UnpaidAmount          float64   `json:"unpaid_amount"`
+    DamageAmount          float64   `json:"damage_amount"`
+    Deposit               float64   `json:"deposit"`
+    DepositOffset         float64   `json:"deposit_offset"`
+    AmountDue             float64   `json:"amount_due"`
+    DepositRefund         float64   `json:"deposit_refund"`
+    QuotedAt              time.Time `json:"quoted_at"`
+    ExpiresAt             time.Time `json:"expires_at"`
+}
+
+type TerminateRequest struct {
+    QuoteID string `json:"quote_id"`
+    Reason  string `json:"reason"`
+}
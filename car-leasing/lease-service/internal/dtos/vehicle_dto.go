@@ -0,0 +1,68 @@
+package dtos
+
+import "time"
+
+type Vehicle struct {
+    ID            string    `json:"id"`
+    Make          string    `json:"make"`
+    Model         string    `json:"model"`
+    Year          int       `json:"year"`
+    VIN           string    `json:"vin"`
+    LicensePlate  *string   `json:"license_plate,omitempty"`
+    VehicleType   string    `json:"vehicle_type"`
+    Color         *string   `json:"color,omitempty"`
+    Mileage       int       `json:"mileage"`
+    PricePerMonth float64   `json:"price_per_month"`
+    DepositAmount *float64  `json:"deposit_amount,omitempty"`
+    Description   *string   `json:"description,omitempty"`
+    ImageURL      *string   `json:"image_url,omitempty"`
+    Available     bool      `json:"available"`
+    NeedsRepair   bool      `json:"needs_repair"`
+    HeldUntil     *time.Time `json:"held_until,omitempty"` // set while another user holds the vehicle
+    CreatedAt     time.Time `json:"created_at"`
+    UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Vehicle types, mirroring the vehicle_type enum in Postgres.
+var VehicleTypes = []string{"SEDAN", "SUV", "TRUCK", "VAN", "SPORTS", "HYBRID", "ELECTRIC"}
+
+type VehicleCreateRequest struct {
+    Make          string   `json:"make"`
+    Model         string   `json:"model"`
+    Year          int      `json:"year"`
+    VIN           string   `json:"vin"`
+    LicensePlate  *string  `json:"license_plate"`
+    VehicleType   string   `json:"vehicle_type"`
+    Color         *string  `json:"color"`
+    Mileage       int      `json:"mileage"`
+    PricePerMonth float64  `json:"price_per_month"`
+    DepositAmount *float64 `json:"deposit_amount"`
+    Description   *string  `json:"description"`
+    ImageURL      *string  `json:"image_url"`
+}
+
+// VehicleUpdateRequest is a partial update; nil fields are left unchanged.
+// Availability is not part of it: the service maintains it.
+type VehicleUpdateRequest struct {
+    Make          *string  `json:"make"`
+    Model         *string  `json:"model"`
+    Year          *int     `json:"year"`
+    LicensePlate  *string  `json:"license_plate"`
+    VehicleType   *string  `json:"vehicle_type"`
+    Color         *string  `json:"color"`
+    Mileage       *int     `json:"mileage"`
+    PricePerMonth *float64 `json:"price_per_month"`
+    DepositAmount *float64 `json:"deposit_amount"`
+    Description   *string  `json:"description"`
+    ImageURL      *string  `json:"image_url"`
+    NeedsRepair   *bool    `json:"needs_repair"`
+}
+
+type VehicleFilter struct {
+    VehicleType string
+    Available   *bool
+    ViewerID    string   // holds by this user don't make vehicles unavailable
+    Held        []string // ids held by other users, unavailable for the Available filter
+    Limit       int
+    Offset      int
+}
@@ -0,0 +1,184 @@
+package pricing
+
+import (
+    "errors"
+    "math"
+    "time"
+
+    "leaseCar/lease-service/internal/dtos"
+)
+
+var (
+    ErrInvalidTerm     = errors.New("lease term must be at least one month")
+    ErrInvalidDeposit  = errors.New("deposit must be non-negative and below the capitalized cost")
+    ErrInvalidResidual = errors.New("residual_percent must be at least 0 and below 1")
+    ErrInvalidLateFee  = errors.New("late_fee_type must be flat, percent or daily")
+)
+
+// Config holds the lender-side pricing parameters, loaded from the
+// `pricing` section of the service config.
+type Config struct {
+    MoneyFactor     float64 `mapstructure:"money_factor"`
+    ResidualPercent float64 `mapstructure:"residual_percent"`
+    AcquisitionFee  float64 `mapstructure:"acquisition_fee"`
+    DocFee          float64 `mapstructure:"doc_fee"`
+
+    // Early termination fee: a share of the remaining installments, never
+    // less than the minimum.
+    EarlyTerminationPercent float64 `mapstructure:"early_termination_percent"`
+    EarlyTerminationMinFee  float64 `mapstructure:"early_termination_min_fee"`
+
+    // Charged per mile driven beyond the lease's mileage_limit.
+    MileageOverageRate float64 `mapstructure:"mileage_overage_rate"`
+
+    // Late fees on overdue items, charged once LateFeeGraceDays have passed
+    // since the due date and never above LateFeeCap (0 means no cap).
+    LateFeeType      string  `mapstructure:"late_fee_type"` // flat, percent or daily
+    LateFeeAmount    float64 `mapstructure:"late_fee_amount"`
+    LateFeePercent   float64 `mapstructure:"late_fee_percent"`
+    LateFeeGraceDays int     `mapstructure:"late_fee_grace_days"`
+    LateFeeCap       float64 `mapstructure:"late_fee_cap"`
+}
+
+// Late fee types.
+const (
+    LateFeeFlat    = "flat"
+    LateFeePercent = "percent"
+    LateFeeDaily   = "daily"
+)
+
+func DefaultConfig() Config {
+    return Config{MoneyFactor: 0.0015, ResidualPercent: 0.55, AcquisitionFee: 595, DocFee: 85,
+        EarlyTerminationPercent: 0.5, EarlyTerminationMinFee: 250, MileageOverageRate: 0.25,
+        LateFeeType: LateFeeFlat, LateFeeAmount: 35, LateFeePercent: 0.05, LateFeeGraceDays: 5, LateFeeCap: 100}
+}
+
+// Validate rejects parameters the pricing model cannot work with; it is
+// checked at startup so a bad config never reaches a quote.
+func (c Config) Validate() error {
+    if c.ResidualPercent < 0 || c.ResidualPercent >= 1 {
+        return ErrInvalidResidual
+    }
+    if c.MoneyFactor < 0 || c.AcquisitionFee < 0 || c.DocFee < 0 ||
+        c.EarlyTerminationPercent < 0 || c.EarlyTerminationMinFee < 0 || c.MileageOverageRate < 0 ||
+        c.LateFeeAmount < 0 || c.LateFeePercent < 0 || c.LateFeeGraceDays < 0 || c.LateFeeCap < 0 {
+        return errors.New("money_factor and fees must not be negative")
+    }
+    switch c.LateFeeType {
+    case LateFeeFlat, LateFeePercent, LateFeeDaily:
+    default:
+        return ErrInvalidLateFee
+    }
+    return nil
+}
+
+type Input struct {
+    PricePerMonth float64
+    TermMonths    int
+    Deposit       float64
+}
+
+type Calculator struct {
+    conf Config
+}
+
+func NewCalculator(conf Config) *Calculator {
+    return &Calculator{conf: conf}
+}
+
+// Calculate prices a lease with the standard depreciation + rent charge
+// model. The vehicle's price_per_month is treated as its list depreciation
+// rate, which together with the residual percentage implies the gross
+// capitalized cost. Fees are capitalized and the deposit reduces the
+// capitalized cost. TotalCost assumes whole months; a dated lease whose last
+// month is partial bills less, see BuildSchedule in the services package.
+func (c *Calculator) Calculate(in Input) (*dtos.LeasePricing, error) {
+    if in.TermMonths < 1 {
+        return nil, ErrInvalidTerm
+    }
+    if c.conf.ResidualPercent < 0 || c.conf.ResidualPercent >= 1 {
+        return nil, ErrInvalidResidual
+    }
+    term := float64(in.TermMonths)
+
+    grossCap := in.PricePerMonth * term / (1 - c.conf.ResidualPercent)
+    residual := grossCap * c.conf.ResidualPercent
+    fees := c.conf.AcquisitionFee + c.conf.DocFee
+    if in.Deposit < 0 || in.Deposit >= grossCap+fees-residual {
+        return nil, ErrInvalidDeposit
+    }
+    adjustedCap := grossCap + fees - in.Deposit
+
+    depreciation := (adjustedCap - residual) / term
+    finance := (adjustedCap + residual) * c.conf.MoneyFactor
+    monthly := roundCents(depreciation + finance)
+
+    return &dtos.LeasePricing{
+        TermMonths:          in.TermMonths,
+        PricePerMonth:       in.PricePerMonth,
+        GrossCapCost:        roundCents(grossCap),
+        Fees:                roundCents(fees),
+        Deposit:             roundCents(in.Deposit),
+        AdjustedCapCost:     roundCents(adjustedCap),
+        ResidualValue:       roundCents(residual),
+        MoneyFactor:         c.conf.MoneyFactor,
+        DepreciationMonthly: roundCents(depreciation),
+        FinanceMonthly:      roundCents(finance),
+        MonthlyPayment:      monthly,
+        TotalCost:           roundCents(monthly*term + in.Deposit),
+    }, nil
+}
+
+// EarlyTerminationFee prices ending a lease with remaining installments
+// still to be billed. Nothing is charged when nothing remains.
+func (c *Calculator) EarlyTerminationFee(remaining float64) float64 {
+    if remaining <= 0 {
+        return 0
+    }
+    return roundCents(math.Max(remaining*c.conf.EarlyTerminationPercent, c.conf.EarlyTerminationMinFee))
+}
+
+// MileageOverageCharge prices the miles driven beyond the allowance.
+func (c *Calculator) MileageOverageCharge(excessMiles int) float64 {
+    if excessMiles <= 0 {
+        return 0
+    }
+    return roundCents(float64(excessMiles) * c.conf.MileageOverageRate)
+}
+
+// LateFee prices the fee for an item of the given amount that is daysLate
+// past due. A flat fee charges LateFeeAmount once, a percent fee charges
+// LateFeePercent of the item once, and a daily fee charges LateFeeAmount for
+// every day past the grace period.
+func (c *Calculator) LateFee(itemAmount float64, daysLate int) float64 {
+    if daysLate <= c.conf.LateFeeGraceDays || itemAmount <= 0 {
+        return 0
+    }
+    var fee float64
+    switch c.conf.LateFeeType {
+    case LateFeeFlat:
+        fee = c.conf.LateFeeAmount
+    case LateFeePercent:
+        fee = itemAmount * c.conf.LateFeePercent
+    case LateFeeDaily:
+        fee = c.conf.LateFeeAmount * float64(daysLate-c.conf.LateFeeGraceDays)
+    }
+    if c.conf.LateFeeCap > 0 {
+        fee = math.Min(fee, c.conf.LateFeeCap)
+    }
+    return roundCents(fee)
+}
+
+// TermMonths counts the months between start and end, rounding a trailing
+// partial month up.
+func TermMonths(start, end time.Time) int {
+    months := (end.Year()-start.Year())*12 + int(end.Month()) - int(start.Month())
+    if end.Day() > start.Day() {
+        months++
+    }
+    return months
+}
+
+func roundCents(v float64) float64 {
+    return math.Round(v*100) / 100
+}
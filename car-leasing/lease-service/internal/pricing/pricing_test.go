@@ -0,0 +1,189 @@
+package pricing
+
+import (
+    "errors"
+    "testing"
+    "time"
+)
+
+func TestCalculate(t *testing.T) {
+    calc := NewCalculator(DefaultConfig())
+    // 300/month over 36 months at a 55% residual: gross cap 24000,
+    // residual 13200, fees 680, adjusted cap 22680 after the deposit
+    p, err := calc.Calculate(Input{PricePerMonth: 300, TermMonths: 36, Deposit: 2000})
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := map[string][2]float64{
+        "gross_cap_cost":       {p.GrossCapCost, 24000},
+        "residual_value":       {p.ResidualValue, 13200},
+        "fees":                 {p.Fees, 680},
+        "adjusted_cap_cost":    {p.AdjustedCapCost, 22680},
+        "depreciation_monthly": {p.DepreciationMonthly, 263.33},
+        "finance_monthly":      {p.FinanceMonthly, 53.82},
+        "monthly_payment":      {p.MonthlyPayment, 317.15},
+        "total_cost":           {p.TotalCost, 13417.40},
+    }
+    for field, v := range want {
+        if v[0] != v[1] {
+            t.Errorf("%s = %.2f, want %.2f", field, v[0], v[1])
+        }
+    }
+}
+
+func TestCalculateDepositLowersPayment(t *testing.T) {
+    calc := NewCalculator(DefaultConfig())
+    none, err := calc.Calculate(Input{PricePerMonth: 300, TermMonths: 36})
+    if err != nil {
+        t.Fatal(err)
+    }
+    some, err := calc.Calculate(Input{PricePerMonth: 300, TermMonths: 36, Deposit: 3000})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if some.MonthlyPayment >= none.MonthlyPayment {
+        t.Fatalf("monthly with deposit %.2f, without %.2f", some.MonthlyPayment, none.MonthlyPayment)
+    }
+}
+
+func TestCalculateRejectsInvalidInput(t *testing.T) {
+    calc := NewCalculator(DefaultConfig())
+    tests := []struct {
+        name string
+        in   Input
+        err  error
+    }{
+        {name: "zero term", in: Input{PricePerMonth: 300}, err: ErrInvalidTerm},
+        {name: "negative deposit", in: Input{PricePerMonth: 300, TermMonths: 36, Deposit: -1}, err: ErrInvalidDeposit},
+        // the deposit must stay below gross cap + fees - residual = 11480
+        {name: "deposit covers the depreciation", in: Input{PricePerMonth: 300, TermMonths: 36, Deposit: 11480}, err: ErrInvalidDeposit},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if _, err := calc.Calculate(tt.in); !errors.Is(err, tt.err) {
+                t.Fatalf("Calculate = %v, want %v", err, tt.err)
+            }
+        })
+    }
+}
+
+func TestConfigValidate(t *testing.T) {
+    tests := []struct {
+        name  string
+        conf  func(c *Config)
+        valid bool
+    }{
+        {name: "default", conf: func(c *Config) {}, valid: true},
+        {name: "no residual", conf: func(c *Config) { c.ResidualPercent = 0 }, valid: true},
+        {name: "full residual", conf: func(c *Config) { c.ResidualPercent = 1 }},
+        {name: "residual above 1", conf: func(c *Config) { c.ResidualPercent = 1.2 }},
+        {name: "negative residual", conf: func(c *Config) { c.ResidualPercent = -0.1 }},
+        {name: "negative money factor", conf: func(c *Config) { c.MoneyFactor = -0.001 }},
+        {name: "negative fee", conf: func(c *Config) { c.DocFee = -1 }},
+        {name: "negative early termination fee", conf: func(c *Config) { c.EarlyTerminationMinFee = -1 }},
+        {name: "negative mileage overage rate", conf: func(c *Config) { c.MileageOverageRate = -0.1 }},
+        {name: "unknown late fee type", conf: func(c *Config) { c.LateFeeType = "weekly" }},
+        {name: "no late fee type", conf: func(c *Config) { c.LateFeeType = "" }},
+        {name: "negative late fee", conf: func(c *Config) { c.LateFeeAmount = -5 }},
+        {name: "negative grace period", conf: func(c *Config) { c.LateFeeGraceDays = -1 }},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            c := DefaultConfig()
+            tt.conf(&c)
+            if err := c.Validate(); (err == nil) != tt.valid {
+                t.Fatalf("Validate = %v, want valid %v", err, tt.valid)
+            }
+        })
+    }
+}
+
+func TestCalculateRejectsFullResidual(t *testing.T) {
+    c := DefaultConfig()
+    c.ResidualPercent = 1
+    if _, err := NewCalculator(c).Calculate(Input{PricePerMonth: 300, TermMonths: 36}); !errors.Is(err, ErrInvalidResidual) {
+        t.Fatalf("Calculate = %v, want %v", err, ErrInvalidResidual)
+    }
+}
+
+func TestEarlyTerminationFee(t *testing.T) {
+    calc := NewCalculator(DefaultConfig())
+    tests := []struct {
+        remaining, want float64
+    }{
+        {0, 0},
+        {-10, 0},
+        {200, 250},   // minimum fee
+        {1000, 500},  // 50% of remaining
+        {333.33, 250},
+    }
+    for _, tt := range tests {
+        if got := calc.EarlyTerminationFee(tt.remaining); got != tt.want {
+            t.Errorf("EarlyTerminationFee(%.2f) = %.2f, want %.2f", tt.remaining, got, tt.want)
+        }
+    }
+}
+
+func TestMileageOverageCharge(t *testing.T) {
+    calc := NewCalculator(DefaultConfig())
+    tests := []struct {
+        excess int
+        want   float64
+    }{
+        {0, 0},
+        {-500, 0},
+        {1, 0.25},
+        {1234, 308.5},
+    }
+    for _, tt := range tests {
+        if got := calc.MileageOverageCharge(tt.excess); got != tt.want {
+            t.Errorf("MileageOverageCharge(%d) = %.2f, want %.2f", tt.excess, got, tt.want)
+        }
+    }
+}
+
+func TestLateFee(t *testing.T) {
+    conf := DefaultConfig()
+    tests := []struct {
+        name     string
+        feeType  string
+        amount   float64
+        daysLate int
+        want     float64
+    }{
+        {name: "within grace", feeType: LateFeeFlat, amount: 300, daysLate: 5, want: 0},
+        {name: "flat", feeType: LateFeeFlat, amount: 300, daysLate: 6, want: 35},
+        {name: "percent", feeType: LateFeePercent, amount: 300, daysLate: 10, want: 15},
+        {name: "percent capped", feeType: LateFeePercent, amount: 5000, daysLate: 10, want: 100},
+        {name: "daily", feeType: LateFeeDaily, amount: 300, daysLate: 7, want: 70},
+        {name: "daily capped", feeType: LateFeeDaily, amount: 300, daysLate: 30, want: 100},
+        {name: "nothing owed", feeType: LateFeeFlat, amount: 0, daysLate: 30, want: 0},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            c := conf
+            c.LateFeeType = tt.feeType
+            if got := NewCalculator(c).LateFee(tt.amount, tt.daysLate); got != tt.want {
+                t.Fatalf("LateFee = %.2f, want %.2f", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestTermMonths(t *testing.T) {
+    day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+    tests := []struct {
+        start, end time.Time
+        want       int
+    }{
+        {day(2026, 1, 1), day(2029, 1, 1), 36},
+        {day(2026, 1, 15), day(2026, 2, 15), 1},
+        {day(2026, 1, 15), day(2026, 2, 16), 2}, // trailing partial month rounds up
+        {day(2026, 1, 31), day(2026, 2, 28), 1},
+    }
+    for _, tt := range tests {
+        if got := TermMonths(tt.start, tt.end); got != tt.want {
+            t.Errorf("TermMonths(%s, %s) = %d, want %d", tt.start.Format("2006-01-02"), tt.end.Format("2006-01-02"), got, tt.want)
+        }
+    }
+}
@@ -0,0 +1,127 @@
+package repositories
+
+import (
+    "context"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+    "leaseCar/lease-service/internal/dtos"
+)
+
+// Amend applies an amendment to the lease as read by the caller: the update
+// is guarded on l.UpdatedAt and returns pgx.ErrNoRows if the lease changed
+// in between. A new monthly payment reprices pending installments due on or
+// after the effective date in proportion; total_cost moves by that
+// difference, and so does pricing's total_cost when a new breakdown is
+// given, so the stored breakdown keeps matching the lease. The amendment gets the next version number and is mirrored
+// into audit_log, all in one transaction.
+func (r *LeaseRepository) Amend(ctx context.Context, l *dtos.Lease, a *dtos.LeaseAmendment, in *dtos.LeaseAmendRequest, pricing *dtos.LeasePricing) (*dtos.LeaseAmendment, error) {
+    tx, err := r.pool.Begin(ctx)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback(ctx)
+
+    var costDelta float64
+    if in.MonthlyPayment != nil && l.Monthly > 0 {
+        costDelta, err = repriceInstallments(ctx, tx, l.ID, l.Monthly, *in.MonthlyPayment, a.EffectiveDate)
+        if err != nil {
+            return nil, err
+        }
+    }
+
+    sql := `UPDATE leases SET monthly_payment = COALESCE($3, monthly_payment), mileage_limit = COALESCE($4, mileage_limit),
+                notes = COALESCE($5, notes), total_cost = COALESCE(total_cost, 0) + $6, updated_at = $7,
+                pricing_breakdown = CASE WHEN $8::jsonb IS NULL THEN pricing_breakdown
+                    ELSE $8::jsonb || jsonb_build_object('total_cost', ROUND((COALESCE(total_cost, 0) + $6)::numeric, 2)) END
+            WHERE id = $1 AND updated_at = $2`
+    tag, err := tx.Exec(ctx, sql, l.ID, l.UpdatedAt, in.MonthlyPayment, in.MileageLimit, in.Notes, costDelta, time.Now(), pricing)
+    if err != nil {
+        return nil, err
+    }
+    if tag.RowsAffected() == 0 {
+        return nil, pgx.ErrNoRows
+    }
+
+    sql = `INSERT INTO lease_amendments (lease_id, version, actor_id, reason, effective_date, changes)
+           VALUES ($1, (SELECT COALESCE(MAX(version), 0) + 1 FROM lease_amendments WHERE lease_id = $1), $2, $3, $4, $5)
+           RETURNING id, version, created_at`
+    if err := tx.QueryRow(ctx, sql, l.ID, a.ActorID, a.Reason, a.EffectiveDate, a.Changes).Scan(&a.ID, &a.Version, &a.CreatedAt); err != nil {
+        return nil, err
+    }
+
+    sql = `INSERT INTO audit_log (user_id, action, entity_type, entity_id, changes) VALUES ($1, 'LEASE_AMENDED', 'lease', $2, $3)`
+    if _, err := tx.Exec(ctx, sql, a.ActorID, l.ID, a); err != nil {
+        return nil, err
+    }
+    return a, tx.Commit(ctx)
+}
+
+func (r *LeaseRepository) ListAmendments(ctx context.Context, leaseID string) ([]dtos.LeaseAmendment, error) {
+    sql := `SELECT id, lease_id, version, actor_id, reason, effective_date, changes, created_at
+            FROM lease_amendments WHERE lease_id = $1 ORDER BY version`
+    rows, err := r.pool.Query(ctx, sql, leaseID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    amendments := []dtos.LeaseAmendment{}
+    for rows.Next() {
+        var a dtos.LeaseAmendment
+        if err := rows.Scan(&a.ID, &a.LeaseID, &a.Version, &a.ActorID, &a.Reason, &a.EffectiveDate, &a.Changes, &a.CreatedAt); err != nil {
+            return nil, err
+        }
+        amendments = append(amendments, a)
+    }
+    return amendments, rows.Err()
+}
+
+func (r *LeaseRepository) ListExtensions(ctx context.Context, leaseID string) ([]dtos.LeaseExtension, error) {
+    sql := `SELECT id, lease_id, previous_end_date, new_end_date, added_installments, added_amount, COALESCE(reason, ''), created_at
+            FROM lease_extensions WHERE lease_id = $1 ORDER BY created_at`
+    rows, err := r.pool.Query(ctx, sql, leaseID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    extensions := []dtos.LeaseExtension{}
+    for rows.Next() {
+        var e dtos.LeaseExtension
+        if err := rows.Scan(&e.ID, &e.LeaseID, &e.PreviousEndDate, &e.NewEndDate, &e.AddedInstallments, &e.AddedAmount, &e.Reason, &e.CreatedAt); err != nil {
+            return nil, err
+        }
+        extensions = append(extensions, e)
+    }
+    return extensions, rows.Err()
+}
+
+// repriceInstallments scales pending installments due on or after from by
+// newMonthly/oldMonthly, so prorated months stay prorated, and returns the
+// total change in amount.
+func repriceInstallments(ctx context.Context, tx pgx.Tx, leaseID string, oldMonthly, newMonthly float64, from time.Time) (float64, error) {
+    sql := `WITH old AS (
+                SELECT id, amount FROM lease_payments
+                WHERE lease_id = $1 AND kind = 'INSTALLMENT' AND status = 'PENDING' AND due_date >= $4::date
+                FOR UPDATE
+            )
+            UPDATE lease_payments p SET amount = ROUND(old.amount * $3 / $2, 2), updated_at = NOW()
+            FROM old WHERE p.id = old.id
+            RETURNING p.amount - old.amount`
+    rows, err := tx.Query(ctx, sql, leaseID, oldMonthly, newMonthly, from)
+    if err != nil {
+        return 0, err
+    }
+    defer rows.Close()
+
+    var delta float64
+    for rows.Next() {
+        var d float64
+        if err := rows.Scan(&d); err != nil {
+            return 0, err
+        }
+        delta += d
+    }
+    return delta, rows.Err()
+}
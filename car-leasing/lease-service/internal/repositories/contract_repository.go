@@ -0,0 +1,43 @@
+package repositories
+
+import (
+    "context"
+
+    "github.com/jackc/pgx/v5/pgxpool"
+    "leaseCar/lease-service/internal/dtos"
+)
+
+type ContractRepository struct {
+    pool *pgxpool.Pool
+}
+
+func NewContractRepository(pool *pgxpool.Pool) *ContractRepository {
+    return &ContractRepository{pool: pool}
+}
+
+// GetLessee loads the contact details printed on a contract.
+func (r *ContractRepository) GetLessee(ctx context.Context, userID string) (*dtos.ContractParty, error) {
+    sql := `SELECT id, TRIM(COALESCE(first_name, '') || ' ' || COALESCE(last_name, '')), email, COALESCE(phone, '')
+            FROM users WHERE id = $1`
+    var p dtos.ContractParty
+    if err := r.pool.QueryRow(ctx, sql, userID).Scan(&p.UserID, &p.Name, &p.Email, &p.Phone); err != nil {
+        return nil, err
+    }
+    return &p, nil
+}
+
+// Record stores a generated document's hash. Regenerating an identical
+// document returns the row from its first generation.
+func (r *ContractRepository) Record(ctx context.Context, c *dtos.LeaseContract) (*dtos.LeaseContract, error) {
+    sql := `INSERT INTO lease_contracts (lease_id, sha256, size_bytes, lease_updated_at)
+            VALUES ($1, $2, $3, $4)
+            ON CONFLICT (lease_id, sha256) DO UPDATE SET sha256 = EXCLUDED.sha256
+            RETURNING id, lease_id, sha256, size_bytes, lease_updated_at, generated_at`
+    var out dtos.LeaseContract
+    err := r.pool.QueryRow(ctx, sql, c.LeaseID, c.SHA256, c.SizeBytes, c.LeaseUpdatedAt).
+        Scan(&out.ID, &out.LeaseID, &out.SHA256, &out.SizeBytes, &out.LeaseUpdatedAt, &out.GeneratedAt)
+    if err != nil {
+        return nil, err
+    }
+    return &out, nil
+}
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+    "context"
+
+    "github.com/jackc/pgx/v5/pgxpool"
+    "leaseCar/lease-service/internal/dtos"
+)
+
+type CreditDecisionRepository struct {
+    pool *pgxpool.Pool
+}
+
+func NewCreditDecisionRepository(pool *pgxpool.Pool) *CreditDecisionRepository {
+    return &CreditDecisionRepository{pool: pool}
+}
+
+func (r *CreditDecisionRepository) Create(ctx context.Context, d *dtos.CreditDecision) error {
+    sql := `INSERT INTO lease_credit_decisions (lease_id, outcome, decisioner, score, reasons, inputs)
+            VALUES ($1,$2,$3,$4,$5,$6) RETURNING id, created_at`
+    return r.pool.QueryRow(ctx, sql, d.LeaseID, d.Outcome, d.Decisioner, d.Score, d.Reasons, d.Inputs).Scan(&d.ID, &d.CreatedAt)
+}
+
+func (r *CreditDecisionRepository) ListByLease(ctx context.Context, leaseID string) ([]dtos.CreditDecision, error) {
+    sql := `SELECT id, lease_id, outcome, decisioner, score, reasons, inputs, created_at
+            FROM lease_credit_decisions WHERE lease_id = $1 ORDER BY created_at`
+    rows, err := r.pool.Query(ctx, sql, leaseID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    decisions := []dtos.CreditDecision{}
+    for rows.Next() {
+        var d dtos.CreditDecision
+        if err := rows.Scan(&d.ID, &d.LeaseID, &d.Outcome, &d.Decisioner, &d.Score, &d.Reasons, &d.Inputs, &d.CreatedAt); err != nil {
+            return nil, err
+        }
+        decisions = append(decisions, d)
+    }
+    return decisions, rows.Err()
+}
+
+// UserExposure counts a user's APPROVED and ACTIVE leases other than
+// excludeID and sums what is still owed on them: unpaid items for active
+// leases, total cost less deposit for approved ones.
+func (r *CreditDecisionRepository) UserExposure(ctx context.Context, userID, excludeID string) (int, float64, error) {
+    sql := `SELECT COUNT(*), COALESCE(SUM(CASE
+                WHEN l.status = 'ACTIVE' THEN (
+                    SELECT COALESCE(SUM(p.amount - COALESCE(p.paid_amount, 0)), 0) FROM lease_payments p
+                    WHERE p.lease_id = l.id AND p.status IN ('PENDING', 'OVERDUE'))
+                ELSE COALESCE(l.total_cost, 0) - COALESCE(l.deposit_paid, 0)
+            END), 0)
+            FROM leases l
+            WHERE l.user_id = $1 AND l.id <> $2 AND l.status IN ('APPROVED', 'ACTIVE')`
+    var count int
+    var exposure float64
+    err := r.pool.QueryRow(ctx, sql, userID, excludeID).Scan(&count, &exposure)
+    return count, exposure, err
+}
@@ -0,0 +1,134 @@
+package repositories
+
+import (
+    "context"
+    "errors"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgxpool"
+    "leaseCar/lease-service/internal/dtos"
+)
+
+const inspectionColumns = `id, lease_id, inspector_id, odometer, checklist, photos, COALESCE(notes, ''), damage_total, deposit_applied, amount_due, deposit_refund, requires_repair, inspected_at`
+
+var (
+    // ErrLeaseChanged means the lease left ACTIVE or its deposit moved since
+    // the caller read it.
+    ErrLeaseChanged = errors.New("lease changed since it was read")
+    // ErrRenewalOpen means a renewal of the lease still awaits approval and
+    // may yet take the deposit over or hand it back.
+    ErrRenewalOpen = errors.New("lease has a renewal awaiting approval")
+)
+
+type InspectionRepository struct {
+    pool *pgxpool.Pool
+}
+
+func NewInspectionRepository(pool *pgxpool.Pool) *InspectionRepository {
+    return &InspectionRepository{pool: pool}
+}
+
+// Create stores an inspection of lease l, as read by the caller, with its
+// damage items in one transaction. The lease row is locked first so no
+// renewal can take the deposit over while it is settled: the lease must
+// still be ACTIVE with the deposit the settlement was built from
+// (ErrLeaseChanged) and have no DRAFT or PENDING_APPROVAL renewal
+// (ErrRenewalOpen). The final odometer is also recorded as the lease's last
+// odometer reading (pgx.ErrNoRows if it is below an earlier one), and the
+// vehicle's mileage and repair flag are updated.
+func (r *InspectionRepository) Create(ctx context.Context, l *dtos.Lease, ins *dtos.LeaseInspection) (*dtos.LeaseInspection, error) {
+    tx, err := r.pool.Begin(ctx)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback(ctx)
+
+    var renewalOpen bool
+    sql := `SELECT EXISTS (SELECT 1 FROM leases WHERE previous_lease_id = l.id AND status IN ('DRAFT', 'PENDING_APPROVAL'))
+            FROM leases l WHERE l.id = $1 AND l.status = 'ACTIVE' AND l.deposit_transferred = $2
+            FOR UPDATE OF l`
+    err = tx.QueryRow(ctx, sql, l.ID, l.DepositTransferred).Scan(&renewalOpen)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrLeaseChanged
+    }
+    if err != nil {
+        return nil, err
+    }
+    if renewalOpen {
+        return nil, ErrRenewalOpen
+    }
+
+    now := time.Now()
+    sql = `INSERT INTO lease_odometer_readings (lease_id, reading, recorded_at)
+            SELECT $1, $2, $3
+            WHERE NOT EXISTS (SELECT 1 FROM lease_odometer_readings WHERE lease_id = $1 AND reading > $2)
+            RETURNING id`
+    var readingID string
+    if err := tx.QueryRow(ctx, sql, ins.LeaseID, ins.Odometer, now).Scan(&readingID); err != nil {
+        return nil, err
+    }
+
+    sql = `INSERT INTO lease_inspections (lease_id, inspector_id, odometer, checklist, photos, notes, damage_total, deposit_applied,
+               amount_due, deposit_refund, requires_repair, inspected_at)
+           VALUES ($1,$2,$3,$4,$5,NULLIF($6, ''),$7,$8,$9,$10,$11,$12) RETURNING id`
+    err = tx.QueryRow(ctx, sql, ins.LeaseID, ins.InspectorID, ins.Odometer, ins.Checklist, ins.Photos, ins.Notes, ins.DamageTotal,
+        ins.DepositApplied, ins.AmountDue, ins.DepositRefund, ins.RequiresRepair, now).Scan(&ins.ID)
+    if err != nil {
+        return nil, err
+    }
+    ins.InspectedAt = now
+
+    if len(ins.Damages) > 0 {
+        sql = `INSERT INTO lease_inspection_damages (inspection_id, area, description, severity, repair_cost, photos)
+               VALUES ($1,$2,$3,$4,$5,$6) RETURNING id`
+        batch := &pgx.Batch{}
+        for _, d := range ins.Damages {
+            batch.Queue(sql, ins.ID, d.Area, d.Description, d.Severity, d.RepairCost, d.Photos)
+        }
+        results := tx.SendBatch(ctx, batch)
+        for i := range ins.Damages {
+            if err := results.QueryRow().Scan(&ins.Damages[i].ID); err != nil {
+                results.Close()
+                return nil, err
+            }
+        }
+        if err := results.Close(); err != nil {
+            return nil, err
+        }
+    }
+
+    sql = `UPDATE vehicles SET mileage = GREATEST(COALESCE(mileage, 0), $2), needs_repair = needs_repair OR $3, updated_at = $4 WHERE id = $1`
+    if _, err := tx.Exec(ctx, sql, l.VehicleID, ins.Odometer, ins.RequiresRepair, now); err != nil {
+        return nil, err
+    }
+    return ins, tx.Commit(ctx)
+}
+
+// GetByLease returns the lease's inspection, or pgx.ErrNoRows if it has not
+// been inspected.
+func (r *InspectionRepository) GetByLease(ctx context.Context, leaseID string) (*dtos.LeaseInspection, error) {
+    sql := `SELECT ` + inspectionColumns + ` FROM lease_inspections WHERE lease_id = $1`
+    var ins dtos.LeaseInspection
+    err := r.pool.QueryRow(ctx, sql, leaseID).Scan(&ins.ID, &ins.LeaseID, &ins.InspectorID, &ins.Odometer, &ins.Checklist, &ins.Photos, &ins.Notes,
+        &ins.DamageTotal, &ins.DepositApplied, &ins.AmountDue, &ins.DepositRefund, &ins.RequiresRepair, &ins.InspectedAt)
+    if err != nil {
+        return nil, err
+    }
+
+    sql = `SELECT id, area, description, severity, repair_cost, photos FROM lease_inspection_damages WHERE inspection_id = $1 ORDER BY id`
+    rows, err := r.pool.Query(ctx, sql, ins.ID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    ins.Damages = []dtos.InspectionDamage{}
+    for rows.Next() {
+        var d dtos.InspectionDamage
+        if err := rows.Scan(&d.ID, &d.Area, &d.Description, &d.Severity, &d.RepairCost, &d.Photos); err != nil {
+            return nil, err
+        }
+        ins.Damages = append(ins.Damages, d)
+    }
+    return &ins, rows.Err()
+}
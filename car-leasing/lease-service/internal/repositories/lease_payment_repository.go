@@ -0,0 +1,129 @@
+package repositories
+
+import (
+    "context"
+    "errors"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgxpool"
+    "leaseCar/lease-service/internal/dtos"
+)
+
+const leasePaymentColumns = `id, lease_id, payment_number, due_date, amount, paid_amount, paid_at, status, kind, COALESCE(description, ''), related_payment_id`
+
+type LeasePaymentRepository struct {
+    pool *pgxpool.Pool
+}
+
+func NewLeasePaymentRepository(pool *pgxpool.Pool) *LeasePaymentRepository {
+    return &LeasePaymentRepository{pool: pool}
+}
+
+func (r *LeasePaymentRepository) ListByLease(ctx context.Context, leaseID string) ([]dtos.LeasePayment, error) {
+    sql := `SELECT ` + leasePaymentColumns + ` FROM lease_payments WHERE lease_id = $1 ORDER BY payment_number`
+    return r.queryPayments(ctx, sql, leaseID)
+}
+
+// MarkOverdue flips up to limit PENDING items due before day to OVERDUE and
+// returns them. Rows locked by another transaction are skipped rather than
+// waited on, so concurrent runs never mark the same item twice.
+func (r *LeasePaymentRepository) MarkOverdue(ctx context.Context, day time.Time, limit int) ([]dtos.LeasePayment, error) {
+    sql := `UPDATE lease_payments SET status = 'OVERDUE', updated_at = $3
+            WHERE id IN (
+                SELECT id FROM lease_payments
+                WHERE status = 'PENDING' AND due_date < $1
+                ORDER BY due_date LIMIT $2
+                FOR UPDATE SKIP LOCKED
+            )
+            RETURNING ` + leasePaymentColumns
+    return r.queryPayments(ctx, sql, day, limit, time.Now())
+}
+
+// ListOverdueChargeable returns OVERDUE items that can carry a late fee
+// (everything except late fees themselves), oldest first, starting after
+// the item with id afterID.
+func (r *LeasePaymentRepository) ListOverdueChargeable(ctx context.Context, afterID string, limit int) ([]dtos.LeasePayment, error) {
+    sql := `SELECT ` + leasePaymentColumns + ` FROM lease_payments
+            WHERE status = 'OVERDUE' AND kind <> 'LATE_FEE' AND ($1 = '' OR id > $1::uuid)
+            ORDER BY id LIMIT $2`
+    return r.queryPayments(ctx, sql, afterID, limit)
+}
+
+// UpsertLateFee records the late fee for an overdue item. A new fee is
+// numbered after the lease's last item; an existing one is updated in place
+// while still unpaid, which is how daily fees accrue; it keeps the due date
+// of the first assessment. It returns the fee's
+// id, or "" when the existing fee was left unchanged.
+func (r *LeasePaymentRepository) UpsertLateFee(ctx context.Context, fee *dtos.LeasePayment) (string, error) {
+    sql := `INSERT INTO lease_payments (lease_id, payment_number, due_date, amount, status, kind, description, related_payment_id)
+            VALUES ($1, (SELECT COALESCE(MAX(payment_number), 0) + 1 FROM lease_payments WHERE lease_id = $1), $2, $3, 'PENDING', 'LATE_FEE', $4, $5)
+            ON CONFLICT (related_payment_id) WHERE kind = 'LATE_FEE' DO UPDATE
+                SET amount = EXCLUDED.amount, description = EXCLUDED.description,
+                    status = 'PENDING', updated_at = NOW()
+                WHERE lease_payments.status IN ('PENDING', 'OVERDUE') AND lease_payments.paid_amount = 0
+                  AND lease_payments.amount <> EXCLUDED.amount
+            RETURNING id`
+    var id string
+    err := r.pool.QueryRow(ctx, sql, fee.LeaseID, fee.DueDate, fee.Amount, fee.Description, fee.RelatedPaymentID).Scan(&id)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return "", nil
+    }
+    return id, err
+}
+
+func (r *LeasePaymentRepository) queryPayments(ctx context.Context, sql string, args ...interface{}) ([]dtos.LeasePayment, error) {
+    rows, err := r.pool.Query(ctx, sql, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    payments := []dtos.LeasePayment{}
+    for rows.Next() {
+        var p dtos.LeasePayment
+        if err := rows.Scan(&p.ID, &p.LeaseID, &p.PaymentNumber, &p.DueDate, &p.Amount, &p.PaidAmount, &p.PaidAt, &p.Status, &p.Kind, &p.Description, &p.RelatedPaymentID); err != nil {
+            return nil, err
+        }
+        payments = append(payments, p)
+    }
+    return payments, rows.Err()
+}
+
+// insertSchedule writes installments for a lease inside tx. Rows that already
+// exist for the same payment number are left untouched.
+func insertSchedule(ctx context.Context, tx pgx.Tx, leaseID string, schedule []dtos.LeasePayment) error {
+    sql := `INSERT INTO lease_payments (lease_id, payment_number, due_date, amount, status, kind, description)
+            VALUES ($1,$2,$3,$4,$5,$6,NULLIF($7, '')) ON CONFLICT (lease_id, payment_number) DO NOTHING`
+    batch := &pgx.Batch{}
+    for _, p := range schedule {
+        kind := p.Kind
+        if kind == "" {
+            kind = dtos.LeasePaymentInstallment
+        }
+        batch.Queue(sql, leaseID, p.PaymentNumber, p.DueDate, p.Amount, p.Status, kind, p.Description)
+    }
+    return tx.SendBatch(ctx, batch).Close()
+}
+
+// appendSchedule adds installments after the lease's current last one,
+// renumbering schedule to continue the sequence.
+func appendSchedule(ctx context.Context, tx pgx.Tx, leaseID string, schedule []dtos.LeasePayment) error {
+    var last int
+    if err := tx.QueryRow(ctx, `SELECT COALESCE(MAX(payment_number), 0) FROM lease_payments WHERE lease_id = $1`, leaseID).Scan(&last); err != nil {
+        return err
+    }
+    for i := range schedule {
+        schedule[i].PaymentNumber = last + i + 1
+    }
+    return insertSchedule(ctx, tx, leaseID, schedule)
+}
+
+// cancelInstallmentsAfter cancels every pending installment of a lease due
+// after the given date. Installments already due stay owed.
+func cancelInstallmentsAfter(ctx context.Context, tx pgx.Tx, leaseID string, after time.Time) error {
+    sql := `UPDATE lease_payments SET status = $3, updated_at = NOW()
+            WHERE lease_id = $1 AND status = $4 AND kind = 'INSTALLMENT' AND due_date > $2::date`
+    _, err := tx.Exec(ctx, sql, leaseID, after, dtos.LeasePaymentCancelled, dtos.LeasePaymentPending)
+    return err
+}
@@ -2,12 +2,22 @@ package repositories
 
 import (
     "context"
+    "errors"
     "time"
 
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgconn"
     "github.com/jackc/pgx/v5/pgxpool"
     "leaseCar/lease-service/internal/dtos"
 )
 
+const leaseColumns = `id, user_id, vehicle_id, status, start_date, end_date, monthly_payment, deposit_paid, deposit_transferred, total_cost, mileage_limit, created_at, updated_at, approved_at, started_at, ended_at, pricing_breakdown, previous_lease_id, COALESCE(notes, '')`
+
+// querier is satisfied by both *pgxpool.Pool and pgx.Tx.
+type querier interface {
+    QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 type LeaseRepository struct {
     pool *pgxpool.Pool
 }
@@ -16,23 +26,479 @@ func NewLeaseRepository(pool *pgxpool.Pool) *LeaseRepository {
     return &LeaseRepository{pool: pool}
 }
 
-func (r *LeaseRepository) Create(ctx context.Context, in *dtos.LeaseCreateRequest) (string, error) {
+// Create stores a lease priced by the pricing engine; monthly_payment and
+// total_cost always come from p, never from the request. New leases start
+// as DRAFT and do not hold the vehicle yet, but a period that is already
+// held by another lease is refused up front as *OverlapError so the draft
+// cannot be submitted later anyway.
+func (r *LeaseRepository) Create(ctx context.Context, in *dtos.LeaseCreateRequest, p *dtos.LeasePricing) (string, error) {
+    tx, err := r.pool.Begin(ctx)
+    if err != nil {
+        return "", err
+    }
+    defer tx.Rollback(ctx)
+
+    if _, err := tx.Exec(ctx, `SELECT 1 FROM vehicles WHERE id = $1 FOR UPDATE`, in.VehicleID); err != nil {
+        return "", err
+    }
+    conflict, err := findOverlap(ctx, tx, in.VehicleID, in.StartDate, in.EndDate, "")
+    if err != nil {
+        return "", err
+    }
+    if conflict != "" {
+        return "", &OverlapError{LeaseID: conflict}
+    }
+
     var id string
-    sql := `INSERT INTO leases (user_id, vehicle_id, start_date, end_date, monthly_payment, deposit_paid, total_cost, mileage_limit)
-            VALUES ($1,$2,$3,$4,$5,$6,$7,$8) RETURNING id`
-    total := in.Monthly // simplification; real total calc elsewhere
-    err := r.pool.QueryRow(ctx, sql, in.UserID, in.VehicleID, in.StartDate, in.EndDate, in.Monthly, in.Deposit, total, in.MileageLimit).Scan(&id)
+    sql := `INSERT INTO leases (user_id, vehicle_id, start_date, end_date, monthly_payment, deposit_paid, total_cost, mileage_limit, pricing_breakdown, previous_lease_id)
+            VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,NULLIF($10, '')::uuid) RETURNING id`
+    err = tx.QueryRow(ctx, sql, in.UserID, in.VehicleID, in.StartDate, in.EndDate, p.MonthlyPayment, p.Deposit, p.TotalCost, in.MileageLimit, p, in.PreviousLeaseID).Scan(&id)
     if err != nil {
         return "", err
     }
-    return id, nil
+    if in.PreviousLeaseID != "" {
+        if err := transferDeposit(ctx, tx, in.PreviousLeaseID, p.Deposit); err != nil {
+            return "", err
+        }
+    }
+    return id, tx.Commit(ctx)
+}
+
+// transferDeposit records on an ACTIVE lease that its renewal took over up
+// to amount of its deposit, so it is not refunded when the lease ends as
+// well. It returns pgx.ErrNoRows when the deposit is no longer available:
+// the lease has left ACTIVE, its deposit was already transferred or its
+// return inspection has settled it.
+func transferDeposit(ctx context.Context, tx pgx.Tx, leaseID string, amount float64) error {
+    // lock first, so the check below sees an inspection committed while
+    // this transaction waited for the row
+    if _, err := tx.Exec(ctx, `SELECT 1 FROM leases WHERE id = $1 FOR UPDATE`, leaseID); err != nil {
+        return err
+    }
+    tag, err := tx.Exec(ctx, `UPDATE leases SET deposit_transferred = LEAST(deposit_paid, $2), updated_at = $3
+            WHERE id = $1 AND status = 'ACTIVE' AND deposit_transferred = 0
+              AND NOT EXISTS (SELECT 1 FROM lease_inspections WHERE lease_id = $1)`, leaseID, amount, time.Now())
+    if err != nil {
+        return err
+    }
+    if tag.RowsAffected() == 0 {
+        return pgx.ErrNoRows
+    }
+    return nil
+}
+
+// returnDeposit undoes transferDeposit once the renewal that took the
+// deposit over is rejected or cancelled. A lease whose return inspection
+// has already settled the deposit without the transferred part keeps it
+// transferred.
+func returnDeposit(ctx context.Context, tx pgx.Tx, leaseID string) error {
+    _, err := tx.Exec(ctx, `UPDATE leases SET deposit_transferred = 0, updated_at = $2
+            WHERE id = $1 AND deposit_transferred > 0
+              AND NOT EXISTS (SELECT 1 FROM lease_inspections WHERE lease_id = $1)`, leaseID, time.Now())
+    return err
 }
 
 func (r *LeaseRepository) GetByID(ctx context.Context, id string) (*dtos.Lease, error) {
-    sql := `SELECT id, user_id, vehicle_id, status, start_date, end_date, monthly_payment, deposit_paid, total_cost, created_at FROM leases WHERE id = $1 LIMIT 1`
-    row := r.pool.QueryRow(ctx, sql, id)
+    sql := `SELECT ` + leaseColumns + ` FROM leases WHERE id = $1 LIMIT 1`
+    return scanLease(r.pool.QueryRow(ctx, sql, id))
+}
+
+// UpdateStatus moves a lease from one status to another and stamps the
+// lifecycle column that belongs to the target status. The update only
+// applies while the lease is still in `from`, so two concurrent transitions
+// cannot both succeed; pgx.ErrNoRows is returned when nothing matched.
+// Any items in schedule are appended to the lease's billable items in the
+// same transaction, and the vehicle's available flag is resynced when a
+// lease starts or ends. Activation also records the baseline odometer
+// reading. A transition that makes the lease hold its vehicle (DRAFT
+// submitted for approval) is checked for overlaps first and fails with
+// *OverlapError.
+func (r *LeaseRepository) UpdateStatus(ctx context.Context, id, from, to string, schedule []dtos.LeasePayment) (*dtos.Lease, error) {
+    tx, err := r.pool.Begin(ctx)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback(ctx)
+
+    var claim struct {
+        vehicleID  string
+        start, end time.Time
+    }
+    if claimsVehicle(from, to) {
+        err := tx.QueryRow(ctx, `SELECT vehicle_id, start_date, end_date FROM leases WHERE id = $1`, id).
+            Scan(&claim.vehicleID, &claim.start, &claim.end)
+        if err != nil {
+            return nil, err
+        }
+        if _, err := tx.Exec(ctx, `SELECT 1 FROM vehicles WHERE id = $1 FOR UPDATE`, claim.vehicleID); err != nil {
+            return nil, err
+        }
+        conflict, err := findOverlap(ctx, tx, claim.vehicleID, claim.start, claim.end, id)
+        if err != nil {
+            return nil, err
+        }
+        if conflict != "" {
+            return nil, &OverlapError{LeaseID: conflict}
+        }
+    }
+
+    l, err := updateStatus(ctx, tx, id, from, to)
+    if err != nil && claimsVehicle(from, to) {
+        return nil, overlapFromConstraint(ctx, r.pool, err, claim.vehicleID, claim.start, claim.end, id)
+    }
+    if err != nil {
+        return nil, err
+    }
+    if len(schedule) > 0 {
+        if err := appendSchedule(ctx, tx, id, schedule); err != nil {
+            return nil, err
+        }
+    }
+    if affectsAvailability(to) {
+        if err := syncVehicleAvailability(ctx, tx, l.VehicleID); err != nil {
+            return nil, err
+        }
+    }
+    if to == dtos.LeaseStatusActive {
+        if err := recordBaselineReading(ctx, tx, l); err != nil {
+            return nil, err
+        }
+    }
+    // a renewal that is rejected or cancelled hands the deposit back to the
+    // lease it renews
+    if l.PreviousLeaseID != nil && (to == dtos.LeaseStatusRejected || to == dtos.LeaseStatusCancelled) {
+        if err := returnDeposit(ctx, tx, *l.PreviousLeaseID); err != nil {
+            return nil, err
+        }
+    }
+    return l, tx.Commit(ctx)
+}
+
+// recordBaselineReading stores the vehicle's mileage at activation as the
+// lease's first odometer reading, so miles driven can be measured from a
+// single reading at the end of the lease.
+func recordBaselineReading(ctx context.Context, tx pgx.Tx, l *dtos.Lease) error {
+    _, err := tx.Exec(ctx, `INSERT INTO lease_odometer_readings (lease_id, reading, recorded_at)
+            SELECT $1, v.mileage, $3 FROM vehicles v
+            WHERE v.id = $2 AND v.mileage IS NOT NULL
+              AND NOT EXISTS (SELECT 1 FROM lease_odometer_readings WHERE lease_id = $1)`, l.ID, l.VehicleID, l.StartedAt)
+    return err
+}
+
+// Terminate ends a lease early: it moves the lease to TERMINATED, cancels
+// installments due after today, appends the final charges, records the
+// accepted quote (if any) and releases the vehicle, all in one transaction.
+func (r *LeaseRepository) Terminate(ctx context.Context, id, from string, quote *dtos.TerminationQuote, charges []dtos.LeasePayment) (*dtos.Lease, error) {
+    tx, err := r.pool.Begin(ctx)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback(ctx)
+
+    l, err := updateStatus(ctx, tx, id, from, dtos.LeaseStatusTerminated)
+    if err != nil {
+        return nil, err
+    }
+    if err := cancelInstallmentsAfter(ctx, tx, id, time.Now()); err != nil {
+        return nil, err
+    }
+    if len(charges) > 0 {
+        if err := appendSchedule(ctx, tx, id, charges); err != nil {
+            return nil, err
+        }
+    }
+    if quote != nil {
+        if _, err := tx.Exec(ctx, `UPDATE leases SET termination_quote = $2 WHERE id = $1`, id, quote); err != nil {
+            return nil, err
+        }
+    }
+    if err := syncVehicleAvailability(ctx, tx, l.VehicleID); err != nil {
+        return nil, err
+    }
+    return l, tx.Commit(ctx)
+}
+
+// Extend pushes an active lease's end date out to newEnd, appends the extra
+// installments to its schedule and records the extension. The update is
+// guarded on the end date the caller read, and the vehicle must be free for
+// the added period; conflicts are reported as *OverlapError.
+func (r *LeaseRepository) Extend(ctx context.Context, l *dtos.Lease, newEnd time.Time, schedule []dtos.LeasePayment, reason string) (*dtos.LeaseExtension, error) {
+    tx, err := r.pool.Begin(ctx)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback(ctx)
+
+    if _, err := tx.Exec(ctx, `SELECT 1 FROM vehicles WHERE id = $1 FOR UPDATE`, l.VehicleID); err != nil {
+        return nil, err
+    }
+    conflict, err := findOverlap(ctx, tx, l.VehicleID, l.EndDate, newEnd, l.ID)
+    if err != nil {
+        return nil, err
+    }
+    if conflict != "" {
+        return nil, &OverlapError{LeaseID: conflict}
+    }
+
+    var added float64
+    for _, p := range schedule {
+        added += p.Amount
+    }
+    sql := `UPDATE leases SET end_date = $3, total_cost = COALESCE(total_cost, 0) + $4, updated_at = $5
+            WHERE id = $1 AND status = 'ACTIVE' AND end_date = $2::date`
+    tag, err := tx.Exec(ctx, sql, l.ID, l.EndDate, newEnd, added, time.Now())
+    if err != nil {
+        return nil, overlapFromConstraint(ctx, r.pool, err, l.VehicleID, l.EndDate, newEnd, l.ID)
+    }
+    if tag.RowsAffected() == 0 {
+        return nil, pgx.ErrNoRows
+    }
+    if err := appendSchedule(ctx, tx, l.ID, schedule); err != nil {
+        return nil, err
+    }
+
+    ext := &dtos.LeaseExtension{LeaseID: l.ID, PreviousEndDate: l.EndDate, NewEndDate: newEnd, AddedInstallments: len(schedule), AddedAmount: added, Reason: reason}
+    sql = `INSERT INTO lease_extensions (lease_id, previous_end_date, new_end_date, added_installments, added_amount, reason)
+           VALUES ($1,$2,$3,$4,$5,$6) RETURNING id, created_at`
+    if err := tx.QueryRow(ctx, sql, l.ID, l.EndDate, newEnd, len(schedule), added, reason).Scan(&ext.ID, &ext.CreatedAt); err != nil {
+        return nil, err
+    }
+    return ext, tx.Commit(ctx)
+}
+
+// FindOverlap returns the id of a lease holding vehicleID for part of
+// [start, end), or "" if the period is free.
+func (r *LeaseRepository) FindOverlap(ctx context.Context, vehicleID string, start, end time.Time) (string, error) {
+    return findOverlap(ctx, r.pool, vehicleID, start, end, "")
+}
+
+// FindSuccessor returns the id of a renewal of the lease that is still a
+// draft or holds the vehicle, or "".
+func (r *LeaseRepository) FindSuccessor(ctx context.Context, id string) (string, error) {
+    sql := `SELECT id FROM leases WHERE previous_lease_id = $1 AND (status = 'DRAFT' OR status = ANY($2)) LIMIT 1`
+    var successor string
+    err := r.pool.QueryRow(ctx, sql, id, holdingLeaseStatuses).Scan(&successor)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return "", nil
+    }
+    return successor, err
+}
+
+func updateStatus(ctx context.Context, q querier, id, from, to string) (*dtos.Lease, error) {
+    sql := `UPDATE leases SET status = $3, updated_at = $4,
+                approved_at = CASE WHEN $3 = 'APPROVED' THEN $4 ELSE approved_at END,
+                started_at = CASE WHEN $3 = 'ACTIVE' THEN $4 ELSE started_at END,
+                ended_at = CASE WHEN $3 IN ('COMPLETED', 'TERMINATED') THEN $4 ELSE ended_at END
+            WHERE id = $1 AND status = $2
+            RETURNING ` + leaseColumns
+    return scanLease(q.QueryRow(ctx, sql, id, from, to, time.Now()))
+}
+
+// ListByUser returns a user's leases newest first. When afterCreatedAt is
+// set, only leases strictly after the (afterCreatedAt, afterID) key in that
+// order are returned.
+func (r *LeaseRepository) ListByUser(ctx context.Context, userID, status string, afterCreatedAt *time.Time, afterID string, limit int) ([]dtos.Lease, error) {
+    sql := `SELECT ` + leaseColumns + ` FROM leases
+            WHERE user_id = $1
+              AND ($2 = '' OR status::text = $2)
+              AND ($3::timestamp IS NULL OR (created_at, id) < ($3, $4::uuid))
+            ORDER BY created_at DESC, id DESC
+            LIMIT $5`
+    if afterCreatedAt == nil {
+        afterID = "00000000-0000-0000-0000-000000000000"
+    }
+    return r.queryLeases(ctx, sql, userID, status, afterCreatedAt, afterID, limit)
+}
+
+// ListStartingBy returns APPROVED leases whose start_date is on or before
+// day, oldest first. When after is set, only leases strictly after it in
+// that order are returned.
+func (r *LeaseRepository) ListStartingBy(ctx context.Context, day time.Time, after *dtos.Lease, limit int) ([]dtos.Lease, error) {
+    sql := `SELECT ` + leaseColumns + ` FROM leases
+            WHERE status = 'APPROVED' AND start_date <= $1
+              AND ($2::date IS NULL OR (start_date, id) > ($2, $3::uuid))
+            ORDER BY start_date, id LIMIT $4`
+    afterDate, afterID := keysetAfter(after, func(l *dtos.Lease) time.Time { return l.StartDate })
+    return r.queryLeases(ctx, sql, day, afterDate, afterID, limit)
+}
+
+// ListEndingBy returns ACTIVE leases whose end_date is before day and whose
+// return inspection is recorded, oldest first, so a lease still runs through
+// its end_date. When after is set, only leases strictly after it in that
+// order are returned.
+func (r *LeaseRepository) ListEndingBy(ctx context.Context, day time.Time, after *dtos.Lease, limit int) ([]dtos.Lease, error) {
+    sql := `SELECT ` + leaseColumns + ` FROM leases
+            WHERE status = 'ACTIVE' AND end_date < $1
+              AND EXISTS (SELECT 1 FROM lease_inspections i WHERE i.lease_id = leases.id)
+              AND ($2::date IS NULL OR (end_date, id) > ($2, $3::uuid))
+            ORDER BY end_date, id LIMIT $4`
+    afterDate, afterID := keysetAfter(after, func(l *dtos.Lease) time.Time { return l.EndDate })
+    return r.queryLeases(ctx, sql, day, afterDate, afterID, limit)
+}
+
+// keysetAfter turns the last lease of a page into (date, id) keyset
+// arguments; a nil lease means the first page.
+func keysetAfter(after *dtos.Lease, date func(*dtos.Lease) time.Time) (*time.Time, string) {
+    if after == nil {
+        return nil, "00000000-0000-0000-0000-000000000000"
+    }
+    d := date(after)
+    return &d, after.ID
+}
+
+// ListUnindexed returns leases whose search document is missing or stale,
+// oldest change first. Passing the last lease of the previous page as after
+// continues past it, so a page that could not be pushed is not read again
+// within the same pass.
+func (r *LeaseRepository) ListUnindexed(ctx context.Context, after *dtos.Lease, limit int) ([]dtos.Lease, error) {
+    var afterAt time.Time
+    afterID := ""
+    if after != nil {
+        afterAt, afterID = after.UpdatedAt, after.ID
+    }
+    sql := `SELECT ` + leaseColumns + ` FROM leases
+            WHERE (indexed_at IS NULL OR indexed_at < updated_at)
+              AND ($2 = '' OR (updated_at, id) > ($1, $2::uuid))
+            ORDER BY updated_at, id LIMIT $3`
+    return r.queryLeases(ctx, sql, afterAt, afterID, limit)
+}
+
+// ListAfter pages through all leases in id order, for full reindexing.
+func (r *LeaseRepository) ListAfter(ctx context.Context, afterID string, limit int) ([]dtos.Lease, error) {
+    sql := `SELECT ` + leaseColumns + ` FROM leases
+            WHERE ($1 = '' OR id > $1::uuid)
+            ORDER BY id LIMIT $2`
+    return r.queryLeases(ctx, sql, afterID, limit)
+}
+
+// MarkIndexed records that the given lease versions are in the index. A
+// lease changed since it was read keeps its newer updated_at and stays
+// pending.
+func (r *LeaseRepository) MarkIndexed(ctx context.Context, leases []dtos.Lease) error {
+    ids := make([]string, len(leases))
+    versions := make([]time.Time, len(leases))
+    for i, l := range leases {
+        ids[i], versions[i] = l.ID, l.UpdatedAt
+    }
+    sql := `UPDATE leases AS l SET indexed_at = v.updated_at
+            FROM unnest($1::uuid[], $2::timestamp[]) AS v(id, updated_at)
+            WHERE l.id = v.id AND l.updated_at = v.updated_at`
+    _, err := r.pool.Exec(ctx, sql, ids, versions)
+    return err
+}
+
+func (r *LeaseRepository) queryLeases(ctx context.Context, sql string, args ...interface{}) ([]dtos.Lease, error) {
+    rows, err := r.pool.Query(ctx, sql, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    leases := []dtos.Lease{}
+    for rows.Next() {
+        l, err := scanLease(rows)
+        if err != nil {
+            return nil, err
+        }
+        leases = append(leases, *l)
+    }
+    return leases, rows.Err()
+}
+
+// OverlapError reports that the vehicle is already held by another lease
+// for part of the requested period.
+type OverlapError struct {
+    LeaseID string
+}
+
+func (e *OverlapError) Error() string {
+    return "vehicle is already leased for an overlapping period by lease " + e.LeaseID
+}
+
+// holdingLeaseStatuses are the statuses that hold a vehicle for their
+// period. Drafts are left out so an abandoned draft never blocks the
+// vehicle; keep in sync with the leases_vehicle_no_overlap constraint.
+var holdingLeaseStatuses = []string{dtos.LeaseStatusPendingApproval, dtos.LeaseStatusApproved, dtos.LeaseStatusActive}
+
+func holdsVehicle(status string) bool {
+    for _, s := range holdingLeaseStatuses {
+        if s == status {
+            return true
+        }
+    }
+    return false
+}
+
+// claimsVehicle reports whether moving a lease from one status to another
+// makes it start holding its vehicle, which is when overlaps are checked.
+func claimsVehicle(from, to string) bool {
+    return !holdsVehicle(from) && holdsVehicle(to)
+}
+
+// affectsAvailability reports whether a lease entering status can change
+// its vehicle's available flag, which tracks active leases only.
+func affectsAvailability(status string) bool {
+    switch status {
+    case dtos.LeaseStatusActive, dtos.LeaseStatusCompleted, dtos.LeaseStatusTerminated:
+        return true
+    }
+    return false
+}
+
+// findOverlap returns the id of a lease holding vehicleID for a period that
+// intersects [start, end), ignoring excludeID, or "" if there is none.
+func findOverlap(ctx context.Context, q querier, vehicleID string, start, end time.Time, excludeID string) (string, error) {
+    sql := `SELECT id FROM leases
+            WHERE vehicle_id = $1 AND status = ANY($5)
+              AND daterange(start_date, end_date) && daterange($2::date, $3::date)
+              AND ($4 = '' OR id::text <> $4)
+            ORDER BY start_date LIMIT 1`
+    var id string
+    err := q.QueryRow(ctx, sql, vehicleID, start, end, excludeID, holdingLeaseStatuses).Scan(&id)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return "", nil
+    }
+    return id, err
+}
+
+// overlapFromConstraint turns an exclusion violation on
+// leases_vehicle_no_overlap into an *OverlapError naming the lease that won.
+func overlapFromConstraint(ctx context.Context, q querier, err error, vehicleID string, start, end time.Time, excludeID string) error {
+    var pgErr *pgconn.PgError
+    if !errors.As(err, &pgErr) || pgErr.Code != "23P01" {
+        return err
+    }
+    conflict, findErr := findOverlap(ctx, q, vehicleID, start, end, excludeID)
+    if findErr != nil || conflict == "" {
+        return err
+    }
+    return &OverlapError{LeaseID: conflict}
+}
+
+// syncVehicleAvailability recomputes a vehicle's available flag from its
+// repair state and leases, see vehicleAvailable.
+func syncVehicleAvailability(ctx context.Context, tx pgx.Tx, vehicleID string) error {
+    var needsRepair, leased bool
+    sql := `SELECT needs_repair, EXISTS (SELECT 1 FROM leases WHERE vehicle_id = $1 AND status = 'ACTIVE')
+            FROM vehicles WHERE id = $1 FOR UPDATE`
+    if err := tx.QueryRow(ctx, sql, vehicleID).Scan(&needsRepair, &leased); err != nil {
+        return err
+    }
+    _, err := tx.Exec(ctx, `UPDATE vehicles SET available = $2, updated_at = $3 WHERE id = $1`,
+        vehicleID, vehicleAvailable(needsRepair, leased), time.Now())
+    return err
+}
+
+// vehicleAvailable reports whether a vehicle can be handed out: it must not
+// be on an active lease or waiting for repair.
+func vehicleAvailable(needsRepair, leased bool) bool {
+    return !needsRepair && !leased
+}
+
+func scanLease(row pgx.Row) (*dtos.Lease, error) {
     var l dtos.Lease
-    err := row.Scan(&l.ID, &l.UserID, &l.VehicleID, &l.Status, &l.StartDate, &l.EndDate, &l.Monthly, &l.Deposit, &l.TotalCost, &l.CreatedAt)
+    err := row.Scan(&l.ID, &l.UserID, &l.VehicleID, &l.Status, &l.StartDate, &l.EndDate, &l.Monthly, &l.Deposit, &l.DepositTransferred, &l.TotalCost, &l.MileageLimit, &l.CreatedAt, &l.UpdatedAt,
+        &l.ApprovedAt, &l.StartedAt, &l.EndedAt, &l.Pricing, &l.PreviousLeaseID, &l.Notes)
     if err != nil {
         return nil, err
     }
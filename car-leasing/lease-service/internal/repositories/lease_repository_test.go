@@ -0,0 +1,84 @@
+package repositories
+
+import (
+    "testing"
+
+    "leaseCar/lease-service/internal/dtos"
+)
+
+func TestHoldsVehicle(t *testing.T) {
+    tests := []struct {
+        status string
+        want   bool
+    }{
+        {dtos.LeaseStatusDraft, false},
+        {dtos.LeaseStatusPendingApproval, true},
+        {dtos.LeaseStatusApproved, true},
+        {dtos.LeaseStatusActive, true},
+        {dtos.LeaseStatusRejected, false},
+        {dtos.LeaseStatusCompleted, false},
+        {dtos.LeaseStatusTerminated, false},
+        {dtos.LeaseStatusCancelled, false},
+    }
+    for _, tt := range tests {
+        if got := holdsVehicle(tt.status); got != tt.want {
+            t.Errorf("holdsVehicle(%s) = %v, want %v", tt.status, got, tt.want)
+        }
+    }
+}
+
+func TestClaimsVehicle(t *testing.T) {
+    tests := []struct {
+        from, to string
+        want     bool
+    }{
+        {dtos.LeaseStatusDraft, dtos.LeaseStatusPendingApproval, true},
+        {dtos.LeaseStatusPendingApproval, dtos.LeaseStatusApproved, false},
+        {dtos.LeaseStatusPendingApproval, dtos.LeaseStatusRejected, false},
+        {dtos.LeaseStatusApproved, dtos.LeaseStatusActive, false},
+        {dtos.LeaseStatusActive, dtos.LeaseStatusCompleted, false},
+        {dtos.LeaseStatusActive, dtos.LeaseStatusTerminated, false},
+        {dtos.LeaseStatusDraft, dtos.LeaseStatusCancelled, false},
+        {dtos.LeaseStatusPendingApproval, dtos.LeaseStatusCancelled, false},
+    }
+    for _, tt := range tests {
+        if got := claimsVehicle(tt.from, tt.to); got != tt.want {
+            t.Errorf("claimsVehicle(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+        }
+    }
+}
+
+func TestAffectsAvailability(t *testing.T) {
+    tests := []struct {
+        status string
+        want   bool
+    }{
+        {dtos.LeaseStatusPendingApproval, false},
+        {dtos.LeaseStatusApproved, false},
+        {dtos.LeaseStatusRejected, false},
+        {dtos.LeaseStatusActive, true},
+        {dtos.LeaseStatusCompleted, true},
+        {dtos.LeaseStatusTerminated, true},
+    }
+    for _, tt := range tests {
+        if got := affectsAvailability(tt.status); got != tt.want {
+            t.Errorf("affectsAvailability(%s) = %v, want %v", tt.status, got, tt.want)
+        }
+    }
+}
+
+func TestVehicleAvailable(t *testing.T) {
+    tests := []struct {
+        needsRepair, leased, want bool
+    }{
+        {false, false, true},
+        {false, true, false},
+        {true, false, false},
+        {true, true, false},
+    }
+    for _, tt := range tests {
+        if got := vehicleAvailable(tt.needsRepair, tt.leased); got != tt.want {
+            t.Errorf("vehicleAvailable(needsRepair=%v, leased=%v) = %v, want %v", tt.needsRepair, tt.leased, got, tt.want)
+        }
+    }
+}
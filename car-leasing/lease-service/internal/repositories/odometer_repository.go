@@ -0,0 +1,51 @@
+package repositories
+
+import (
+    "context"
+
+    "github.com/jackc/pgx/v5/pgxpool"
+    "leaseCar/lease-service/internal/dtos"
+)
+
+type OdometerRepository struct {
+    pool *pgxpool.Pool
+}
+
+func NewOdometerRepository(pool *pgxpool.Pool) *OdometerRepository {
+    return &OdometerRepository{pool: pool}
+}
+
+// Create stores a reading unless the lease already has a higher one;
+// pgx.ErrNoRows is returned in that case.
+func (r *OdometerRepository) Create(ctx context.Context, leaseID string, in *dtos.OdometerReadingRequest) (*dtos.OdometerReading, error) {
+    sql := `INSERT INTO lease_odometer_readings (lease_id, reading, recorded_at)
+            SELECT $1, $2, $3
+            WHERE NOT EXISTS (SELECT 1 FROM lease_odometer_readings WHERE lease_id = $1 AND reading > $2)
+            RETURNING id, lease_id, reading, recorded_at`
+    var out dtos.OdometerReading
+    err := r.pool.QueryRow(ctx, sql, leaseID, in.Reading, in.RecordedAt).Scan(&out.ID, &out.LeaseID, &out.Reading, &out.RecordedAt)
+    if err != nil {
+        return nil, err
+    }
+    return &out, nil
+}
+
+func (r *OdometerRepository) ListByLease(ctx context.Context, leaseID string) ([]dtos.OdometerReading, error) {
+    sql := `SELECT id, lease_id, reading, recorded_at FROM lease_odometer_readings
+            WHERE lease_id = $1 ORDER BY recorded_at, reading`
+    rows, err := r.pool.Query(ctx, sql, leaseID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    readings := []dtos.OdometerReading{}
+    for rows.Next() {
+        var o dtos.OdometerReading
+        if err := rows.Scan(&o.ID, &o.LeaseID, &o.Reading, &o.RecordedAt); err != nil {
+            return nil, err
+        }
+        readings = append(readings, o)
+    }
+    return readings, rows.Err()
+}
@@ -0,0 +1,212 @@
+package repositories
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgxpool"
+    "leaseCar/lease-service/internal/dtos"
+)
+
+const vehicleColumns = `id, make, model, year, vin, license_plate, vehicle_type, color, mileage, price_per_month, deposit_amount, description, image_url, available, needs_repair, created_at, updated_at`
+
+type VehicleRepository struct {
+    pool *pgxpool.Pool
+}
+
+func NewVehicleRepository(pool *pgxpool.Pool) *VehicleRepository {
+    return &VehicleRepository{pool: pool}
+}
+
+func (r *VehicleRepository) GetByID(ctx context.Context, id string) (*dtos.Vehicle, error) {
+    sql := `SELECT ` + vehicleColumns + ` FROM vehicles WHERE id = $1 LIMIT 1`
+    return scanVehicle(r.pool.QueryRow(ctx, sql, id))
+}
+
+func (r *VehicleRepository) Create(ctx context.Context, in *dtos.VehicleCreateRequest) (*dtos.Vehicle, error) {
+    sql := `INSERT INTO vehicles (make, model, year, vin, license_plate, vehicle_type, color, mileage, price_per_month, deposit_amount, description, image_url)
+            VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12) RETURNING ` + vehicleColumns
+    return scanVehicle(r.pool.QueryRow(ctx, sql, in.Make, in.Model, in.Year, in.VIN, in.LicensePlate, in.VehicleType, in.Color, in.Mileage,
+        in.PricePerMonth, in.DepositAmount, in.Description, in.ImageURL))
+}
+
+func (r *VehicleRepository) List(ctx context.Context, f dtos.VehicleFilter) ([]dtos.Vehicle, error) {
+    var where []string
+    var args []interface{}
+    if f.VehicleType != "" {
+        args = append(args, f.VehicleType)
+        where = append(where, fmt.Sprintf("vehicle_type = $%d", len(args)))
+    }
+    if f.Available != nil {
+        // a nil slice would be sent as NULL and match nothing
+        held := f.Held
+        if held == nil {
+            held = []string{}
+        }
+        args = append(args, held)
+        if *f.Available {
+            where = append(where, fmt.Sprintf("available AND NOT id = ANY($%d::uuid[])", len(args)))
+        } else {
+            where = append(where, fmt.Sprintf("(NOT available OR id = ANY($%d::uuid[]))", len(args)))
+        }
+    }
+
+    sql := `SELECT ` + vehicleColumns + ` FROM vehicles`
+    if len(where) > 0 {
+        sql += ` WHERE ` + strings.Join(where, " AND ")
+    }
+    args = append(args, f.Limit, f.Offset)
+    sql += fmt.Sprintf(` ORDER BY created_at DESC LIMIT $%d OFFSET $%d`, len(args)-1, len(args))
+    return r.queryVehicles(ctx, sql, args...)
+}
+
+// Update applies a partial update. Availability is not writable; it is
+// recomputed in the same transaction, so clearing needs_repair frees a
+// vehicle that is not on an active lease, and setting it takes the vehicle
+// out of service.
+func (r *VehicleRepository) Update(ctx context.Context, id string, in *dtos.VehicleUpdateRequest) (*dtos.Vehicle, error) {
+    tx, err := r.pool.Begin(ctx)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback(ctx)
+
+    sql := `UPDATE vehicles SET
+                make = COALESCE($2, make),
+                model = COALESCE($3, model),
+                year = COALESCE($4, year),
+                license_plate = COALESCE($5, license_plate),
+                vehicle_type = COALESCE($6, vehicle_type),
+                color = COALESCE($7, color),
+                mileage = COALESCE($8, mileage),
+                price_per_month = COALESCE($9, price_per_month),
+                deposit_amount = COALESCE($10, deposit_amount),
+                description = COALESCE($11, description),
+                image_url = COALESCE($12, image_url),
+                needs_repair = COALESCE($13, needs_repair),
+                updated_at = $14
+            WHERE id = $1`
+    tag, err := tx.Exec(ctx, sql, id, in.Make, in.Model, in.Year, in.LicensePlate, in.VehicleType, in.Color, in.Mileage,
+        in.PricePerMonth, in.DepositAmount, in.Description, in.ImageURL, in.NeedsRepair, time.Now())
+    if err != nil {
+        return nil, err
+    }
+    if tag.RowsAffected() == 0 {
+        return nil, pgx.ErrNoRows
+    }
+    if err := syncVehicleAvailability(ctx, tx, id); err != nil {
+        return nil, err
+    }
+    v, err := scanVehicle(tx.QueryRow(ctx, `SELECT `+vehicleColumns+` FROM vehicles WHERE id = $1`, id))
+    if err != nil {
+        return nil, err
+    }
+    return v, tx.Commit(ctx)
+}
+
+// Delete removes a vehicle and queues its search document for removal in the
+// same transaction. Vehicles referenced by a lease cannot be deleted (ON
+// DELETE RESTRICT) and surface as a foreign-key violation.
+func (r *VehicleRepository) Delete(ctx context.Context, id string) error {
+    tx, err := r.pool.Begin(ctx)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+
+    tag, err := tx.Exec(ctx, `DELETE FROM vehicles WHERE id = $1`, id)
+    if err != nil {
+        return err
+    }
+    if tag.RowsAffected() == 0 {
+        return pgx.ErrNoRows
+    }
+    sql := `INSERT INTO vehicle_index_deletions (vehicle_id, deleted_at) VALUES ($1, $2)
+            ON CONFLICT (vehicle_id) DO UPDATE SET deleted_at = EXCLUDED.deleted_at`
+    if _, err := tx.Exec(ctx, sql, id, time.Now()); err != nil {
+        return err
+    }
+    return tx.Commit(ctx)
+}
+
+// ListUnindexed returns vehicles whose search document is missing or stale,
+// oldest change first.
+func (r *VehicleRepository) ListUnindexed(ctx context.Context, limit int) ([]dtos.Vehicle, error) {
+    sql := `SELECT ` + vehicleColumns + ` FROM vehicles
+            WHERE indexed_at IS NULL OR indexed_at < updated_at
+            ORDER BY updated_at LIMIT $1`
+    return r.queryVehicles(ctx, sql, limit)
+}
+
+// MarkIndexed records that the given vehicle versions are in the index. A
+// vehicle changed since it was read keeps its newer updated_at and stays
+// pending.
+func (r *VehicleRepository) MarkIndexed(ctx context.Context, vehicles []dtos.Vehicle) error {
+    ids := make([]string, len(vehicles))
+    versions := make([]time.Time, len(vehicles))
+    for i, v := range vehicles {
+        ids[i], versions[i] = v.ID, v.UpdatedAt
+    }
+    sql := `UPDATE vehicles AS t SET indexed_at = v.updated_at
+            FROM unnest($1::uuid[], $2::timestamp[]) AS v(id, updated_at)
+            WHERE t.id = v.id AND t.updated_at = v.updated_at`
+    _, err := r.pool.Exec(ctx, sql, ids, versions)
+    return err
+}
+
+// ListDeleted returns deleted vehicles whose documents are still to be
+// removed from the index, oldest first.
+func (r *VehicleRepository) ListDeleted(ctx context.Context, limit int) ([]string, error) {
+    rows, err := r.pool.Query(ctx, `SELECT vehicle_id FROM vehicle_index_deletions ORDER BY deleted_at LIMIT $1`, limit)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    ids := []string{}
+    for rows.Next() {
+        var id string
+        if err := rows.Scan(&id); err != nil {
+            return nil, err
+        }
+        ids = append(ids, id)
+    }
+    return ids, rows.Err()
+}
+
+// ClearDeleted forgets deletions whose documents have left the index.
+func (r *VehicleRepository) ClearDeleted(ctx context.Context, ids []string) error {
+    _, err := r.pool.Exec(ctx, `DELETE FROM vehicle_index_deletions WHERE vehicle_id = ANY($1::uuid[])`, ids)
+    return err
+}
+
+func (r *VehicleRepository) queryVehicles(ctx context.Context, sql string, args ...interface{}) ([]dtos.Vehicle, error) {
+    rows, err := r.pool.Query(ctx, sql, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    vehicles := []dtos.Vehicle{}
+    for rows.Next() {
+        v, err := scanVehicle(rows)
+        if err != nil {
+            return nil, err
+        }
+        vehicles = append(vehicles, *v)
+    }
+    return vehicles, rows.Err()
+}
+
+func scanVehicle(row pgx.Row) (*dtos.Vehicle, error) {
+    var v dtos.Vehicle
+    err := row.Scan(&v.ID, &v.Make, &v.Model, &v.Year, &v.VIN, &v.LicensePlate, &v.VehicleType, &v.Color, &v.Mileage,
+        &v.PricePerMonth, &v.DepositAmount, &v.Description, &v.ImageURL, &v.Available, &v.NeedsRepair, &v.CreatedAt, &v.UpdatedAt)
+    if err != nil {
+        return nil, err
+    }
+    return &v, nil
+}
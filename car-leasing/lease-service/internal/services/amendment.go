@@ -0,0 +1,191 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+    "leaseCar/lease-service/internal/dtos"
+    "leaseCar/lease-service/internal/validation"
+)
+
+const ActionAmend = "amend"
+
+// Amend records a versioned change to a lease's monthly payment, mileage
+// limit or notes. Leases can be amended until they end; a new monthly
+// payment reprices the installments due from the effective date on. Before
+// activation the monthly payment still belongs to the pricing engine and
+// the credit decision made on it, so it cannot be amended.
+func (s *LeaseService) Amend(ctx context.Context, id string, in *dtos.LeaseAmendRequest) (*dtos.LeaseAmendment, error) {
+    if in.EffectiveDate.IsZero() {
+        in.EffectiveDate = time.Now()
+    }
+    in.EffectiveDate = dateOnly(in.EffectiveDate)
+    if err := validateAmendment(in); err != nil {
+        return nil, err
+    }
+
+    l, err := s.repo.GetByID(ctx, id)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrLeaseNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    switch l.Status {
+    case dtos.LeaseStatusCompleted, dtos.LeaseStatusTerminated, dtos.LeaseStatusRejected, dtos.LeaseStatusCancelled:
+        return nil, &InvalidTransitionError{Action: ActionAmend, From: l.Status}
+    }
+    if in.MonthlyPayment != nil && l.Status != dtos.LeaseStatusActive {
+        return nil, validation.Errors{{Field: "monthly_payment", Message: "can only be amended once the lease is ACTIVE"}}
+    }
+
+    changes := map[string]dtos.FieldChange{}
+    var unchanged validation.Errors
+    if in.MonthlyPayment != nil {
+        if *in.MonthlyPayment != l.Monthly {
+            changes["monthly_payment"] = dtos.FieldChange{Before: l.Monthly, After: *in.MonthlyPayment}
+        } else {
+            unchanged.Add("monthly_payment", "matches the current value")
+        }
+    }
+    if in.MileageLimit != nil {
+        if *in.MileageLimit != l.MileageLimit {
+            changes["mileage_limit"] = dtos.FieldChange{Before: l.MileageLimit, After: *in.MileageLimit}
+        } else {
+            unchanged.Add("mileage_limit", "matches the current value")
+        }
+    }
+    if in.Notes != nil {
+        if *in.Notes != l.Notes {
+            changes["notes"] = dtos.FieldChange{Before: l.Notes, After: *in.Notes}
+        } else {
+            unchanged.Add("notes", "matches the current value")
+        }
+    }
+    if len(changes) == 0 {
+        return nil, unchanged
+    }
+
+    var pricing *dtos.LeasePricing
+    if _, ok := changes["monthly_payment"]; ok {
+        pricing = amendedPricing(l.Pricing, *in.MonthlyPayment)
+    }
+    actor := in.ActorID
+    a := &dtos.LeaseAmendment{LeaseID: l.ID, ActorID: &actor, Reason: in.Reason, EffectiveDate: in.EffectiveDate, Changes: changes}
+    a, err = s.repo.Amend(ctx, l, a, in, pricing)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrLeaseStatusChanged
+    }
+    if err != nil {
+        return nil, err
+    }
+    s.indexer.Notify()
+    return a, nil
+}
+
+func validateAmendment(in *dtos.LeaseAmendRequest) error {
+    var errs validation.Errors
+    if !validation.IsUUID(in.ActorID) {
+        errs.Add("actor_id", "must be a UUID")
+    }
+    if strings.TrimSpace(in.Reason) == "" {
+        errs.Add("reason", "is required")
+    }
+    if in.EffectiveDate.Before(dateOnly(time.Now())) {
+        errs.Add("effective_date", "must not be in the past")
+    }
+    if in.MonthlyPayment == nil && in.MileageLimit == nil && in.Notes == nil {
+        errs.Add("monthly_payment", "one of monthly_payment, mileage_limit or notes is required")
+    }
+    if in.MonthlyPayment != nil && *in.MonthlyPayment <= 0 {
+        errs.Add("monthly_payment", "must be greater than zero")
+    }
+    if in.MileageLimit != nil && *in.MileageLimit <= 0 {
+        errs.Add("mileage_limit", "must be greater than zero")
+    }
+    return errs.Err()
+}
+
+// amendedPricing is the pricing breakdown after the monthly payment is
+// amended: the depreciation and rent charge parts are scaled to the new
+// payment, while the cap cost, residual and money factor the lease was
+// priced with stay as they were. total_cost is set by the repository, which
+// knows what repricing the schedule added. Leases priced before breakdowns
+// were stored have none, and keep none.
+func amendedPricing(p *dtos.LeasePricing, monthly float64) *dtos.LeasePricing {
+    if p == nil {
+        return nil
+    }
+    out := *p
+    if p.MonthlyPayment > 0 {
+        out.DepreciationMonthly = roundCents(p.DepreciationMonthly * monthly / p.MonthlyPayment)
+    }
+    out.FinanceMonthly = roundCents(monthly - out.DepreciationMonthly)
+    out.MonthlyPayment = monthly
+    return &out
+}
+
+// History returns a lease's timeline, oldest first: creation, lifecycle
+// milestones, extensions and amendments.
+func (s *LeaseService) History(ctx context.Context, id string) (*dtos.LeaseHistory, error) {
+    l, err := s.GetByID(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+    extensions, err := s.repo.ListExtensions(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+    amendments, err := s.repo.ListAmendments(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+    return buildHistory(l, extensions, amendments), nil
+}
+
+func buildHistory(l *dtos.Lease, extensions []dtos.LeaseExtension, amendments []dtos.LeaseAmendment) *dtos.LeaseHistory {
+    entries := []dtos.LeaseHistoryEntry{{Type: dtos.LeaseHistoryCreated, At: l.CreatedAt}}
+    milestones := []struct {
+        typ string
+        at  *time.Time
+    }{
+        {dtos.LeaseHistoryApproved, l.ApprovedAt},
+        {dtos.LeaseHistoryStarted, l.StartedAt},
+        {dtos.LeaseHistoryEnded, l.EndedAt},
+    }
+    for _, m := range milestones {
+        if m.at != nil {
+            e := dtos.LeaseHistoryEntry{Type: m.typ, At: *m.at}
+            if m.typ == dtos.LeaseHistoryEnded {
+                e.Reason = strings.ToLower(l.Status)
+            }
+            entries = append(entries, e)
+        }
+    }
+    for _, x := range extensions {
+        entries = append(entries, dtos.LeaseHistoryEntry{
+            Type:    dtos.LeaseHistoryExtended,
+            At:      x.CreatedAt,
+            Reason:  x.Reason,
+            Changes: map[string]dtos.FieldChange{"end_date": {Before: x.PreviousEndDate, After: x.NewEndDate}},
+        })
+    }
+    for _, a := range amendments {
+        effective := a.EffectiveDate
+        entries = append(entries, dtos.LeaseHistoryEntry{
+            Type:          dtos.LeaseHistoryAmended,
+            At:            a.CreatedAt,
+            Version:       a.Version,
+            ActorID:       a.ActorID,
+            Reason:        a.Reason,
+            EffectiveDate: &effective,
+            Changes:       a.Changes,
+        })
+    }
+    sort.SliceStable(entries, func(i, j int) bool { return entries[i].At.Before(entries[j].At) })
+    return &dtos.LeaseHistory{LeaseID: l.ID, Entries: entries}
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+    "testing"
+    "time"
+
+    "leaseCar/lease-service/internal/dtos"
+    "leaseCar/lease-service/internal/validation"
+)
+
+func TestValidateAmendment(t *testing.T) {
+    today := dateOnly(time.Now())
+    monthly := func(v float64) *float64 { return &v }
+    miles := func(v int) *int { return &v }
+    notes := "moved to a new address"
+    tests := []struct {
+        name   string
+        in     dtos.LeaseAmendRequest
+        fields []string
+    }{
+        {name: "valid", in: dtos.LeaseAmendRequest{ActorID: "6f1c2a7e-3b4d-4e5f-8a9b-0c1d2e3f4a5b", Reason: "renegotiated", EffectiveDate: today, MonthlyPayment: monthly(450)}},
+        {name: "notes only", in: dtos.LeaseAmendRequest{ActorID: "6f1c2a7e-3b4d-4e5f-8a9b-0c1d2e3f4a5b", Reason: "update", EffectiveDate: today.AddDate(0, 1, 0), Notes: &notes}},
+        {name: "missing actor and reason", in: dtos.LeaseAmendRequest{Reason: "  ", EffectiveDate: today, Notes: &notes}, fields: []string{"actor_id", "reason"}},
+        {name: "past effective date", in: dtos.LeaseAmendRequest{ActorID: "6f1c2a7e-3b4d-4e5f-8a9b-0c1d2e3f4a5b", Reason: "late", EffectiveDate: today.AddDate(0, 0, -1), Notes: &notes}, fields: []string{"effective_date"}},
+        {name: "nothing to change", in: dtos.LeaseAmendRequest{ActorID: "6f1c2a7e-3b4d-4e5f-8a9b-0c1d2e3f4a5b", Reason: "none", EffectiveDate: today}, fields: []string{"monthly_payment"}},
+        {name: "non-positive values", in: dtos.LeaseAmendRequest{ActorID: "6f1c2a7e-3b4d-4e5f-8a9b-0c1d2e3f4a5b", Reason: "typo", EffectiveDate: today, MonthlyPayment: monthly(0), MileageLimit: miles(-100)}, fields: []string{"monthly_payment", "mileage_limit"}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            err := validateAmendment(&tt.in)
+            if len(tt.fields) == 0 {
+                if err != nil {
+                    t.Fatalf("validateAmendment = %v, want nil", err)
+                }
+                return
+            }
+            errs, ok := err.(validation.Errors)
+            if !ok {
+                t.Fatalf("validateAmendment = %v, want field errors on %v", err, tt.fields)
+            }
+            if len(errs) != len(tt.fields) {
+                t.Fatalf("got %v, want errors on %v", errs, tt.fields)
+            }
+            for i, f := range tt.fields {
+                if errs[i].Field != f {
+                    t.Errorf("error %d on %q, want %q", i, errs[i].Field, f)
+                }
+            }
+        })
+    }
+}
+
+func TestAmendedPricing(t *testing.T) {
+    p := &dtos.LeasePricing{GrossCapCost: 24000, AdjustedCapCost: 22680, ResidualValue: 13200, MoneyFactor: 0.0015,
+        DepreciationMonthly: 263.33, FinanceMonthly: 53.82, MonthlyPayment: 317.15, TotalCost: 13417.40}
+    got := amendedPricing(p, 400)
+    if got.MonthlyPayment != 400 {
+        t.Errorf("monthly_payment = %.2f, want 400", got.MonthlyPayment)
+    }
+    if got.DepreciationMonthly+got.FinanceMonthly != 400 {
+        t.Errorf("depreciation %.2f + finance %.2f != 400", got.DepreciationMonthly, got.FinanceMonthly)
+    }
+    if got.DepreciationMonthly != 332.12 {
+        t.Errorf("depreciation_monthly = %.2f, want 332.12", got.DepreciationMonthly)
+    }
+    if got.AdjustedCapCost != p.AdjustedCapCost || got.ResidualValue != p.ResidualValue || got.MoneyFactor != p.MoneyFactor {
+        t.Errorf("priced terms changed: %+v", got)
+    }
+    if p.MonthlyPayment != 317.15 {
+        t.Error("the lease's breakdown was modified in place")
+    }
+    if amendedPricing(nil, 400) != nil {
+        t.Error("a lease without a breakdown got one")
+    }
+}
+
+func TestBuildHistory(t *testing.T) {
+    at := func(d int) time.Time { return time.Date(2026, 1, d, 12, 0, 0, 0, time.UTC) }
+    approved, started, ended := at(3), at(5), at(20)
+    actor := "actor-1"
+    l := &dtos.Lease{ID: "lease-1", Status: dtos.LeaseStatusTerminated, CreatedAt: at(1), ApprovedAt: &approved, StartedAt: &started, EndedAt: &ended}
+    extensions := []dtos.LeaseExtension{{PreviousEndDate: day(2027, 1, 1), NewEndDate: day(2027, 7, 1), Reason: "customer request", CreatedAt: at(10)}}
+    amendments := []dtos.LeaseAmendment{
+        {Version: 1, ActorID: &actor, Reason: "renegotiated", EffectiveDate: day(2026, 2, 1), CreatedAt: at(8),
+            Changes: map[string]dtos.FieldChange{"monthly_payment": {Before: 300.0, After: 280.0}}},
+        {Version: 2, ActorID: &actor, Reason: "more miles", EffectiveDate: day(2026, 2, 1), CreatedAt: at(15),
+            Changes: map[string]dtos.FieldChange{"mileage_limit": {Before: 12000, After: 15000}}},
+    }
+
+    h := buildHistory(l, extensions, amendments)
+    want := []string{dtos.LeaseHistoryCreated, dtos.LeaseHistoryApproved, dtos.LeaseHistoryStarted,
+        dtos.LeaseHistoryAmended, dtos.LeaseHistoryExtended, dtos.LeaseHistoryAmended, dtos.LeaseHistoryEnded}
+    if len(h.Entries) != len(want) {
+        t.Fatalf("got %d entries, want %d: %+v", len(h.Entries), len(want), h.Entries)
+    }
+    for i, typ := range want {
+        if h.Entries[i].Type != typ {
+            t.Errorf("entry %d is %s, want %s", i, h.Entries[i].Type, typ)
+        }
+    }
+    if e := h.Entries[3]; e.Version != 1 || e.EffectiveDate == nil || !e.EffectiveDate.Equal(day(2026, 2, 1)) {
+        t.Errorf("first amendment entry = %+v", e)
+    }
+    if e := h.Entries[4]; e.Changes["end_date"].After != day(2027, 7, 1) {
+        t.Errorf("extension entry = %+v", e)
+    }
+    if e := h.Entries[6]; e.Reason != "terminated" {
+        t.Errorf("ended reason = %q, want terminated", e.Reason)
+    }
+}
+
+func TestBuildHistoryNewLease(t *testing.T) {
+    h := buildHistory(&dtos.Lease{ID: "lease-1", Status: dtos.LeaseStatusDraft, CreatedAt: day(2026, 1, 1)}, nil, nil)
+    if len(h.Entries) != 1 || h.Entries[0].Type != dtos.LeaseHistoryCreated {
+        t.Fatalf("got %+v, want only the created entry", h.Entries)
+    }
+}
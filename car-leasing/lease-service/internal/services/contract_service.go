@@ -0,0 +1,90 @@
+package services
+
+import (
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "errors"
+
+    "github.com/jackc/pgx/v5"
+    "leaseCar/lease-service/internal/contract"
+    "leaseCar/lease-service/internal/dtos"
+    "leaseCar/lease-service/internal/repositories"
+)
+
+var ErrLesseeNotFound = errors.New("lessee not found")
+
+type ContractService struct {
+    leases    *repositories.LeaseRepository
+    vehicles  *repositories.VehicleRepository
+    payments  *repositories.LeasePaymentRepository
+    contracts *repositories.ContractRepository
+}
+
+func NewContractService(l *repositories.LeaseRepository, v *repositories.VehicleRepository, p *repositories.LeasePaymentRepository, c *repositories.ContractRepository) *ContractService {
+    return &ContractService{leases: l, vehicles: v, payments: p, contracts: c}
+}
+
+// Render renders the contract for a lease without recording anything, so
+// it is safe to serve on reads. Leases without a schedule yet get the one
+// activation would create.
+func (s *ContractService) Render(ctx context.Context, leaseID string) ([]byte, error) {
+    _, pdf, err := s.render(ctx, leaseID)
+    return pdf, err
+}
+
+// Issue renders the contract and records the document's SHA-256, which is
+// how a version handed to the lessee for signing is fingerprinted.
+func (s *ContractService) Issue(ctx context.Context, leaseID string) (*dtos.LeaseContract, error) {
+    l, pdf, err := s.render(ctx, leaseID)
+    if err != nil {
+        return nil, err
+    }
+    return s.contracts.Record(ctx, &dtos.LeaseContract{
+        LeaseID:        l.ID,
+        SHA256:         ContractSHA256(pdf),
+        SizeBytes:      len(pdf),
+        LeaseUpdatedAt: l.UpdatedAt,
+    })
+}
+
+// ContractSHA256 is the hex SHA-256 a contract document is recorded under.
+func ContractSHA256(pdf []byte) string {
+    sum := sha256.Sum256(pdf)
+    return hex.EncodeToString(sum[:])
+}
+
+func (s *ContractService) render(ctx context.Context, leaseID string) (*dtos.Lease, []byte, error) {
+    l, err := s.leases.GetByID(ctx, leaseID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, nil, ErrLeaseNotFound
+    }
+    if err != nil {
+        return nil, nil, err
+    }
+    vehicle, err := s.vehicles.GetByID(ctx, l.VehicleID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, nil, ErrVehicleNotFound
+    }
+    if err != nil {
+        return nil, nil, err
+    }
+    lessee, err := s.contracts.GetLessee(ctx, l.UserID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, nil, ErrLesseeNotFound
+    }
+    if err != nil {
+        return nil, nil, err
+    }
+    schedule, err := s.payments.ListByLease(ctx, l.ID)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    data := &contract.Data{Lease: l, Vehicle: vehicle, Lessee: lessee, Schedule: schedule}
+    if len(schedule) == 0 {
+        data.Schedule = BuildSchedule(l.StartDate, l.EndDate, l.Monthly)
+        data.Projected = true
+    }
+    return l, contract.Render(data), nil
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "fmt"
+
+    "github.com/jackc/pgx/v5"
+    "leaseCar/lease-service/internal/dtos"
+    "leaseCar/lease-service/internal/repositories"
+    "leaseCar/utils/logger"
+)
+
+// CreditBureau supplies an applicant's credit score and income.
+type CreditBureau interface {
+    Report(ctx context.Context, userID string) (*dtos.CreditReport, error)
+}
+
+// CreditApplication is what a decisioner scores: the submitted lease and
+// the applicant's other commitments.
+type CreditApplication struct {
+    Lease        *dtos.Lease
+    ActiveLeases int
+    Exposure     float64
+}
+
+// CreditDecisioner decides whether a submitted lease is approved, rejected
+// or left for manual review. The returned decision carries its reasons; it
+// is stored by CreditService.
+type CreditDecisioner interface {
+    Name() string
+    Decide(ctx context.Context, app *CreditApplication) (*dtos.CreditDecision, error)
+}
+
+// CreditConfig holds the thresholds of RulesDecisioner, loaded from the
+// `credit` section of the service config.
+type CreditConfig struct {
+    // Bureau selects the CreditBureau: "stub" for development only, empty
+    // when none is integrated, in which case every lease goes to review.
+    Bureau                string  `mapstructure:"bureau"`
+    MinScore              int     `mapstructure:"min_score"`                // below: reject
+    ReviewScore           int     `mapstructure:"review_score"`             // below: review
+    MaxPaymentToIncome    float64 `mapstructure:"max_payment_to_income"`    // above: reject
+    ReviewPaymentToIncome float64 `mapstructure:"review_payment_to_income"` // above: review
+    MaxActiveLeases       int     `mapstructure:"max_active_leases"`        // other leases at or above: reject
+    MaxExposure           float64 `mapstructure:"max_exposure"`             // total owed above: review
+}
+
+func DefaultCreditConfig() CreditConfig {
+    return CreditConfig{MinScore: 580, ReviewScore: 660, MaxPaymentToIncome: 0.2, ReviewPaymentToIncome: 0.12,
+        MaxActiveLeases: 2, MaxExposure: 150000}
+}
+
+// RulesDecisioner scores applications with fixed rules on bureau score,
+// payment-to-income ratio, number of other leases and total exposure. Every
+// rule reports a reason; the strictest outcome wins.
+type RulesDecisioner struct {
+    bureau CreditBureau
+    conf   CreditConfig
+}
+
+func NewRulesDecisioner(b CreditBureau, conf CreditConfig) *RulesDecisioner {
+    return &RulesDecisioner{bureau: b, conf: conf}
+}
+
+func (d *RulesDecisioner) Name() string {
+    return "rules-v1"
+}
+
+func (d *RulesDecisioner) Decide(ctx context.Context, app *CreditApplication) (*dtos.CreditDecision, error) {
+    l := app.Lease
+    report, err := d.bureau.Report(ctx, l.UserID)
+    if err != nil {
+        return nil, err
+    }
+    c := d.conf
+    dec := &dtos.CreditDecision{LeaseID: l.ID, Decisioner: d.Name(), Score: &report.Score, Outcome: dtos.CreditApprove}
+    add := func(rule, outcome, msg string) {
+        dec.Reasons = append(dec.Reasons, dtos.CreditReason{Rule: rule, Outcome: outcome, Message: msg})
+        if severity(outcome) > severity(dec.Outcome) {
+            dec.Outcome = outcome
+        }
+    }
+
+    switch {
+    case report.Score < c.MinScore:
+        add("credit_score", dtos.CreditReject, fmt.Sprintf("score %d is below the minimum of %d", report.Score, c.MinScore))
+    case report.Score < c.ReviewScore:
+        add("credit_score", dtos.CreditReview, fmt.Sprintf("score %d is below %d", report.Score, c.ReviewScore))
+    default:
+        add("credit_score", dtos.CreditApprove, fmt.Sprintf("score %d", report.Score))
+    }
+
+    ratio := 0.0
+    switch {
+    case report.MonthlyIncome <= 0:
+        add("payment_to_income", dtos.CreditReview, "no income reported")
+    default:
+        ratio = l.Monthly / report.MonthlyIncome
+        msg := fmt.Sprintf("monthly payment is %.1f%% of income", ratio*100)
+        switch {
+        case ratio > c.MaxPaymentToIncome:
+            add("payment_to_income", dtos.CreditReject, msg+fmt.Sprintf(", above the %.0f%% limit", c.MaxPaymentToIncome*100))
+        case ratio > c.ReviewPaymentToIncome:
+            add("payment_to_income", dtos.CreditReview, msg+fmt.Sprintf(", above %.0f%%", c.ReviewPaymentToIncome*100))
+        default:
+            add("payment_to_income", dtos.CreditApprove, msg)
+        }
+    }
+
+    if app.ActiveLeases >= c.MaxActiveLeases {
+        add("active_leases", dtos.CreditReject, fmt.Sprintf("user already has %d approved or active leases (limit %d)", app.ActiveLeases, c.MaxActiveLeases))
+    } else {
+        add("active_leases", dtos.CreditApprove, fmt.Sprintf("%d other approved or active leases", app.ActiveLeases))
+    }
+
+    exposure := app.Exposure + l.TotalCost - l.Deposit
+    if exposure > c.MaxExposure {
+        add("exposure", dtos.CreditReview, fmt.Sprintf("total exposure %.2f exceeds %.2f", exposure, c.MaxExposure))
+    } else {
+        add("exposure", dtos.CreditApprove, fmt.Sprintf("total exposure %.2f", exposure))
+    }
+
+    dec.Inputs = map[string]interface{}{
+        "score":             report.Score,
+        "monthly_income":    report.MonthlyIncome,
+        "bureau":            report.Source,
+        "monthly_payment":   l.Monthly,
+        "payment_to_income": roundCents(ratio * 100) / 100,
+        "active_leases":     app.ActiveLeases,
+        "exposure":          roundCents(exposure),
+    }
+    return dec, nil
+}
+
+// ManualReviewDecisioner is used when no credit bureau is configured: it
+// fails closed, sending every submitted lease to manual review.
+type ManualReviewDecisioner struct{}
+
+func NewManualReviewDecisioner() *ManualReviewDecisioner {
+    return &ManualReviewDecisioner{}
+}
+
+func (d *ManualReviewDecisioner) Name() string {
+    return "manual-review"
+}
+
+func (d *ManualReviewDecisioner) Decide(ctx context.Context, app *CreditApplication) (*dtos.CreditDecision, error) {
+    return &dtos.CreditDecision{
+        LeaseID:    app.Lease.ID,
+        Outcome:    dtos.CreditReview,
+        Decisioner: d.Name(),
+        Reasons:    []dtos.CreditReason{{Rule: "credit_bureau", Outcome: dtos.CreditReview, Message: "no credit bureau configured"}},
+        Inputs:     map[string]interface{}{},
+    }, nil
+}
+
+func severity(outcome string) int {
+    switch outcome {
+    case dtos.CreditReject:
+        return 2
+    case dtos.CreditReview:
+        return 1
+    }
+    return 0
+}
+
+// CreditService runs the configured decisioner on submitted leases and
+// stores every decision.
+type CreditService struct {
+    decisioner CreditDecisioner
+    decisions  *repositories.CreditDecisionRepository
+}
+
+func NewCreditService(d CreditDecisioner, r *repositories.CreditDecisionRepository) *CreditService {
+    return &CreditService{decisioner: d, decisions: r}
+}
+
+// Assess scores a lease and records the decision. If the decisioner fails
+// the lease is sent to manual review, and that is recorded too.
+func (s *CreditService) Assess(ctx context.Context, l *dtos.Lease) (*dtos.CreditDecision, error) {
+    count, exposure, err := s.decisions.UserExposure(ctx, l.UserID, l.ID)
+    if err != nil {
+        return nil, err
+    }
+    dec, err := s.decisioner.Decide(ctx, &CreditApplication{Lease: l, ActiveLeases: count, Exposure: exposure})
+    if err != nil {
+        logger.Warn("credit decision failed for lease " + l.ID + ": " + err.Error())
+        dec = &dtos.CreditDecision{
+            LeaseID:    l.ID,
+            Outcome:    dtos.CreditReview,
+            Decisioner: s.decisioner.Name(),
+            Reasons:    []dtos.CreditReason{{Rule: "decisioner", Outcome: dtos.CreditReview, Message: "decisioner unavailable: " + err.Error()}},
+            Inputs:     map[string]interface{}{},
+        }
+    }
+    if err := s.decisions.Create(ctx, dec); err != nil {
+        return nil, err
+    }
+    return dec, nil
+}
+
+// autoDecide applies the credit decision for a freshly submitted lease.
+// Approvals and rejections go through Transition like manual ones; REVIEW,
+// or any failure here, leaves the lease in PENDING_APPROVAL.
+func (s *LeaseService) autoDecide(ctx context.Context, l *dtos.Lease) *dtos.Lease {
+    dec, err := s.credit.Assess(ctx, l)
+    if err != nil {
+        logger.Warn("credit assessment failed for lease " + l.ID + ": " + err.Error())
+        return l
+    }
+    var action string
+    switch dec.Outcome {
+    case dtos.CreditApprove:
+        action = ActionApprove
+    case dtos.CreditReject:
+        action = ActionReject
+    default:
+        return l
+    }
+    decided, err := s.Transition(ctx, l.ID, action, "credit decision "+dec.ID)
+    if err != nil {
+        logger.Warn("automatic " + action + " failed for lease " + l.ID + ": " + err.Error())
+        return l
+    }
+    return decided
+}
+
+// CreditDecisions lists the credit decisions taken for a lease.
+func (s *LeaseService) CreditDecisions(ctx context.Context, id string) ([]dtos.CreditDecision, error) {
+    if _, err := s.repo.GetByID(ctx, id); errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrLeaseNotFound
+    } else if err != nil {
+        return nil, err
+    }
+    return s.credit.decisions.ListByLease(ctx, id)
+}
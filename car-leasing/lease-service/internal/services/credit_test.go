@@ -0,0 +1,129 @@
+package services
+
+import (
+    "context"
+    "testing"
+
+    "leaseCar/lease-service/internal/adapters"
+    "leaseCar/lease-service/internal/dtos"
+)
+
+const creditTestUser = "6f1c2a8e-4b7d-4e59-9a3c-1d2e3f405162"
+
+func stubReport(t *testing.T) *dtos.CreditReport {
+    t.Helper()
+    r, err := adapters.NewStubCreditBureau().Report(context.Background(), creditTestUser)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return r
+}
+
+func TestStubCreditBureauIsDeterministic(t *testing.T) {
+    a, b := stubReport(t), stubReport(t)
+    if *a != *b {
+        t.Fatalf("reports differ: %+v vs %+v", a, b)
+    }
+    if a.Score < 300 || a.Score > 850 {
+        t.Fatalf("score %d outside 300-850", a.Score)
+    }
+    if a.MonthlyIncome < 2000 || a.MonthlyIncome > 14000 {
+        t.Fatalf("income %.2f outside 2000-14000", a.MonthlyIncome)
+    }
+}
+
+// TestRulesDecisionerThresholds moves each threshold around the stub's
+// report for one user, so every rule is exercised on both sides.
+func TestRulesDecisionerThresholds(t *testing.T) {
+    report := stubReport(t)
+    income := report.MonthlyIncome
+    // thresholds that approve everything for this user
+    approve := CreditConfig{MinScore: report.Score, ReviewScore: report.Score, MaxPaymentToIncome: 0.5,
+        ReviewPaymentToIncome: 0.3, MaxActiveLeases: 3, MaxExposure: 100000}
+
+    tests := []struct {
+        name     string
+        conf     func(c *CreditConfig)
+        monthly  float64
+        active   int
+        exposure float64
+        outcome  string
+        rule     string
+    }{
+        {name: "all rules pass", monthly: income * 0.1, outcome: dtos.CreditApprove},
+        {name: "score below minimum", conf: func(c *CreditConfig) { c.MinScore, c.ReviewScore = report.Score+1, report.Score+1 },
+            monthly: income * 0.1, outcome: dtos.CreditReject, rule: "credit_score"},
+        {name: "score below review", conf: func(c *CreditConfig) { c.ReviewScore = report.Score + 1 },
+            monthly: income * 0.1, outcome: dtos.CreditReview, rule: "credit_score"},
+        {name: "payment to income below review", monthly: income * 0.25, outcome: dtos.CreditApprove},
+        {name: "payment to income above review", monthly: income * 0.4, outcome: dtos.CreditReview, rule: "payment_to_income"},
+        {name: "payment to income above maximum", monthly: income * 0.6, outcome: dtos.CreditReject, rule: "payment_to_income"},
+        {name: "active leases below limit", monthly: income * 0.1, active: 2, outcome: dtos.CreditApprove},
+        {name: "active leases at limit", monthly: income * 0.1, active: 3, outcome: dtos.CreditReject, rule: "active_leases"},
+        {name: "exposure above maximum", monthly: income * 0.1, exposure: 99000, outcome: dtos.CreditReview, rule: "exposure"},
+        {name: "reject outranks review", conf: func(c *CreditConfig) { c.ReviewScore = report.Score + 1 },
+            monthly: income * 0.6, outcome: dtos.CreditReject, rule: "payment_to_income"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            conf := approve
+            if tt.conf != nil {
+                tt.conf(&conf)
+            }
+            l := &dtos.Lease{ID: "lease-1", UserID: creditTestUser, Monthly: tt.monthly, TotalCost: 24000, Deposit: 2000}
+            dec, err := NewRulesDecisioner(adapters.NewStubCreditBureau(), conf).Decide(context.Background(),
+                &CreditApplication{Lease: l, ActiveLeases: tt.active, Exposure: tt.exposure})
+            if err != nil {
+                t.Fatal(err)
+            }
+            if dec.Outcome != tt.outcome {
+                t.Fatalf("outcome = %s, want %s (reasons %+v)", dec.Outcome, tt.outcome, dec.Reasons)
+            }
+            if dec.Score == nil || *dec.Score != report.Score {
+                t.Fatalf("score = %v, want %d", dec.Score, report.Score)
+            }
+            if len(dec.Reasons) != 4 {
+                t.Fatalf("got %d reasons, want one per rule", len(dec.Reasons))
+            }
+            if tt.rule != "" && !hasReason(dec.Reasons, tt.rule, tt.outcome) {
+                t.Fatalf("no %s reason for rule %s in %+v", tt.outcome, tt.rule, dec.Reasons)
+            }
+        })
+    }
+}
+
+func TestRulesDecisionerNoIncomeGoesToReview(t *testing.T) {
+    dec, err := NewRulesDecisioner(zeroIncomeBureau{}, DefaultCreditConfig()).Decide(context.Background(),
+        &CreditApplication{Lease: &dtos.Lease{ID: "lease-1", UserID: creditTestUser, Monthly: 400}})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if dec.Outcome != dtos.CreditReview || !hasReason(dec.Reasons, "payment_to_income", dtos.CreditReview) {
+        t.Fatalf("got %s %+v, want REVIEW on payment_to_income", dec.Outcome, dec.Reasons)
+    }
+}
+
+func TestManualReviewDecisionerFailsClosed(t *testing.T) {
+    dec, err := NewManualReviewDecisioner().Decide(context.Background(), &CreditApplication{Lease: &dtos.Lease{ID: "lease-1"}})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if dec.Outcome != dtos.CreditReview {
+        t.Fatalf("outcome = %s, want REVIEW", dec.Outcome)
+    }
+}
+
+type zeroIncomeBureau struct{}
+
+func (zeroIncomeBureau) Report(ctx context.Context, userID string) (*dtos.CreditReport, error) {
+    return &dtos.CreditReport{UserID: userID, Score: 800, Source: "test"}, nil
+}
+
+func hasReason(reasons []dtos.CreditReason, rule, outcome string) bool {
+    for _, r := range reasons {
+        if r.Rule == rule && r.Outcome == outcome {
+            return true
+        }
+    }
+    return false
+}
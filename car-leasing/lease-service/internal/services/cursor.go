@@ -0,0 +1,33 @@
+package services
+
+import (
+    "encoding/base64"
+    "errors"
+    "strings"
+    "time"
+
+    "leaseCar/lease-service/internal/validation"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// encodeCursor builds an opaque keyset cursor from the last row of a page.
+func encodeCursor(createdAt time.Time, id string) string {
+    return base64.RawURLEncoding.EncodeToString([]byte(createdAt.Format(time.RFC3339Nano) + "|" + id))
+}
+
+func decodeCursor(cursor string) (time.Time, string, error) {
+    raw, err := base64.RawURLEncoding.DecodeString(cursor)
+    if err != nil {
+        return time.Time{}, "", ErrInvalidCursor
+    }
+    ts, id, ok := strings.Cut(string(raw), "|")
+    if !ok || !validation.IsUUID(id) {
+        return time.Time{}, "", ErrInvalidCursor
+    }
+    createdAt, err := time.Parse(time.RFC3339Nano, ts)
+    if err != nil {
+        return time.Time{}, "", ErrInvalidCursor
+    }
+    return createdAt, id, nil
+}
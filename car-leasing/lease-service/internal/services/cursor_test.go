@@ -0,0 +1,41 @@
+package services
+
+import (
+    "encoding/base64"
+    "errors"
+    "testing"
+    "time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+    createdAt := time.Date(2026, 3, 14, 9, 26, 53, 589793000, time.UTC)
+    id := "3f2b7c1e-9a4d-4e6f-8b1a-2c3d4e5f6a7b"
+
+    gotAt, gotID, err := decodeCursor(encodeCursor(createdAt, id))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !gotAt.Equal(createdAt) || gotID != id {
+        t.Fatalf("decoded (%s, %s), want (%s, %s)", gotAt, gotID, createdAt, id)
+    }
+}
+
+func TestDecodeCursorRejectsMalformed(t *testing.T) {
+    encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+    tests := []struct {
+        name, cursor string
+    }{
+        {"not base64", "%%%"},
+        {"no separator", encode("2026-03-14T09:26:53Z")},
+        {"id not a uuid", encode("2026-03-14T09:26:53Z|1 OR 1=1")},
+        {"bad timestamp", encode("yesterday|3f2b7c1e-9a4d-4e6f-8b1a-2c3d4e5f6a7b")},
+        {"empty", ""},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if _, _, err := decodeCursor(tt.cursor); !errors.Is(err, ErrInvalidCursor) {
+                t.Fatalf("got %v, want ErrInvalidCursor", err)
+            }
+        })
+    }
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "strings"
+
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgconn"
+    "leaseCar/lease-service/internal/dtos"
+    "leaseCar/lease-service/internal/repositories"
+    "leaseCar/lease-service/internal/validation"
+)
+
+var (
+    ErrInspectionRequired = errors.New("lease needs a return inspection before it can be completed")
+    ErrAlreadyInspected   = errors.New("lease already has a return inspection")
+    ErrRenewalPending     = errors.New("lease has a renewal awaiting approval; approve, reject or cancel it before the inspection")
+)
+
+type InspectionService struct {
+    leases      *repositories.LeaseRepository
+    inspections *repositories.InspectionRepository
+}
+
+func NewInspectionService(l *repositories.LeaseRepository, i *repositories.InspectionRepository) *InspectionService {
+    return &InspectionService{leases: l, inspections: i}
+}
+
+// Record stores the return inspection of an ACTIVE lease and settles the
+// damage against its deposit. The lease itself is completed or terminated
+// separately. While a renewal still awaits approval the deposit it carries
+// over is not final, so such leases cannot be inspected yet.
+func (s *InspectionService) Record(ctx context.Context, leaseID string, in *dtos.InspectionRequest) (*dtos.LeaseInspection, error) {
+    if err := validateInspection(in); err != nil {
+        return nil, err
+    }
+    l, err := s.leases.GetByID(ctx, leaseID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrLeaseNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    if l.Status != dtos.LeaseStatusActive {
+        return nil, &InvalidTransitionError{Action: "inspect", From: l.Status}
+    }
+
+    ins := buildInspection(l, in)
+    ins, err = s.inspections.Create(ctx, l, ins)
+    var pgErr *pgconn.PgError
+    switch {
+    case errors.Is(err, repositories.ErrRenewalOpen):
+        return nil, ErrRenewalPending
+    case errors.Is(err, repositories.ErrLeaseChanged):
+        return nil, ErrLeaseStatusChanged
+    case errors.Is(err, pgx.ErrNoRows):
+        return nil, validation.Errors{{Field: "odometer", Message: "is lower than an earlier reading for this lease"}}
+    case errors.As(err, &pgErr) && pgErr.Code == "23505":
+        return nil, ErrAlreadyInspected
+    case errors.As(err, &pgErr) && pgErr.Code == "23503":
+        return nil, validation.Errors{{Field: "inspector_id", Message: "does not exist"}}
+    case err != nil:
+        return nil, err
+    }
+    return ins, nil
+}
+
+func (s *InspectionService) Get(ctx context.Context, leaseID string) (*dtos.LeaseInspection, error) {
+    ins, err := s.inspections.GetByLease(ctx, leaseID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrInspectionRequired
+    }
+    return ins, err
+}
+
+// DamageCharge returns the damage left over after the deposit as a billable
+// item for completion or termination, or nil when the deposit covered it. It fails with
+// ErrInspectionRequired if the lease has not been inspected.
+func (s *InspectionService) DamageCharge(ctx context.Context, l *dtos.Lease) (*dtos.LeasePayment, error) {
+    ins, err := s.Get(ctx, l.ID)
+    if err != nil {
+        return nil, err
+    }
+    if ins.AmountDue <= 0 {
+        return nil, nil
+    }
+    return &dtos.LeasePayment{
+        DueDate:     dateOnly(ins.InspectedAt),
+        Amount:      ins.AmountDue,
+        Status:      dtos.LeasePaymentPending,
+        Kind:        dtos.LeasePaymentDamage,
+        Description: fmt.Sprintf("Return damage %.2f less %.2f deposit applied", ins.DamageTotal, ins.DepositApplied),
+    }, nil
+}
+
+// buildInspection totals the damage and applies the deposit to it. Only the
+// deposit still held is settled; any part carried over to a renewal stays
+// with the renewal.
+func buildInspection(l *dtos.Lease, in *dtos.InspectionRequest) *dtos.LeaseInspection {
+    ins := &dtos.LeaseInspection{
+        LeaseID:   l.ID,
+        Odometer:  in.Odometer,
+        Checklist: in.Checklist,
+        Damages:   in.Damages,
+        Photos:    in.Photos,
+        Notes:     in.Notes,
+    }
+    if in.InspectorID != "" {
+        inspector := in.InspectorID
+        ins.InspectorID = &inspector
+    }
+    if ins.Photos == nil {
+        ins.Photos = []string{}
+    }
+    if ins.Damages == nil {
+        ins.Damages = []dtos.InspectionDamage{}
+    }
+    for i, d := range ins.Damages {
+        if d.Photos == nil {
+            ins.Damages[i].Photos = []string{}
+        }
+        ins.DamageTotal += d.RepairCost
+        if d.Severity == dtos.DamageSevere {
+            ins.RequiresRepair = true
+        }
+    }
+    ins.DamageTotal = roundCents(ins.DamageTotal)
+    deposit := l.DepositHeld()
+    ins.DepositApplied = roundCents(min(deposit, ins.DamageTotal))
+    ins.AmountDue = roundCents(ins.DamageTotal - ins.DepositApplied)
+    ins.DepositRefund = roundCents(deposit - ins.DepositApplied)
+    return ins
+}
+
+func validateInspection(in *dtos.InspectionRequest) error {
+    var errs validation.Errors
+    if in.InspectorID != "" && !validation.IsUUID(in.InspectorID) {
+        errs.Add("inspector_id", "must be a UUID")
+    }
+    if in.Odometer <= 0 {
+        errs.Add("odometer", "must be greater than zero")
+    }
+    covered := map[string]bool{}
+    for _, c := range in.Checklist {
+        covered[strings.ToLower(c.Item)] = true
+    }
+    var missing []string
+    for _, item := range dtos.InspectionChecklist {
+        if !covered[item] {
+            missing = append(missing, item)
+        }
+    }
+    if len(missing) > 0 {
+        errs.Add("checklist", "missing items: "+strings.Join(missing, ", "))
+    }
+    for i, d := range in.Damages {
+        field := fmt.Sprintf("damages[%d].", i)
+        if strings.TrimSpace(d.Area) == "" {
+            errs.Add(field+"area", "is required")
+        }
+        if strings.TrimSpace(d.Description) == "" {
+            errs.Add(field+"description", "is required")
+        }
+        switch d.Severity {
+        case dtos.DamageMinor, dtos.DamageModerate, dtos.DamageSevere:
+        default:
+            errs.Add(field+"severity", "must be one of MINOR, MODERATE, SEVERE")
+        }
+        if d.RepairCost < 0 {
+            errs.Add(field+"repair_cost", "must not be negative")
+        }
+    }
+    return errs.Err()
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+    "testing"
+    "time"
+
+    "leaseCar/lease-service/internal/dtos"
+    "leaseCar/lease-service/internal/pricing"
+    "leaseCar/lease-service/internal/validation"
+)
+
+func TestBuildInspectionSettlement(t *testing.T) {
+    damage := func(severity string, cost float64) dtos.InspectionDamage {
+        return dtos.InspectionDamage{Area: "rear bumper", Description: "dent", Severity: severity, RepairCost: cost}
+    }
+    tests := []struct {
+        name                  string
+        deposit, transferred  float64
+        damages               []dtos.InspectionDamage
+        applied, due, refund  float64
+        requiresRepair        bool
+    }{
+        {name: "no damage", deposit: 1500, applied: 0, due: 0, refund: 1500},
+        {name: "covered by the deposit", deposit: 1500, damages: []dtos.InspectionDamage{damage(dtos.DamageMinor, 120.5), damage(dtos.DamageModerate, 420)},
+            applied: 540.5, due: 0, refund: 959.5},
+        {name: "beyond the deposit", deposit: 500, damages: []dtos.InspectionDamage{damage(dtos.DamageSevere, 1800)},
+            applied: 500, due: 1300, refund: 0, requiresRepair: true},
+        {name: "no deposit", damages: []dtos.InspectionDamage{damage(dtos.DamageModerate, 300)}, applied: 0, due: 300, refund: 0},
+        // the part carried over to a renewal is not this lease's to settle
+        {name: "deposit partly carried over", deposit: 1500, transferred: 1000, damages: []dtos.InspectionDamage{damage(dtos.DamageModerate, 800)},
+            applied: 500, due: 300, refund: 0},
+        {name: "deposit fully carried over", deposit: 1500, transferred: 1500, damages: []dtos.InspectionDamage{damage(dtos.DamageMinor, 80)},
+            applied: 0, due: 80, refund: 0},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            l := &dtos.Lease{ID: "lease-1", Deposit: tt.deposit, DepositTransferred: tt.transferred}
+            ins := buildInspection(l, &dtos.InspectionRequest{Odometer: 36120, Damages: tt.damages})
+            got := map[string][2]float64{
+                "deposit_applied": {ins.DepositApplied, tt.applied},
+                "amount_due":      {ins.AmountDue, tt.due},
+                "deposit_refund":  {ins.DepositRefund, tt.refund},
+            }
+            for field, v := range got {
+                if v[0] != v[1] {
+                    t.Errorf("%s = %.2f, want %.2f", field, v[0], v[1])
+                }
+            }
+            if ins.DepositApplied+ins.AmountDue != ins.DamageTotal {
+                t.Errorf("applied %.2f + due %.2f != damage %.2f", ins.DepositApplied, ins.AmountDue, ins.DamageTotal)
+            }
+            if ins.RequiresRepair != tt.requiresRepair {
+                t.Errorf("requires_repair = %v, want %v", ins.RequiresRepair, tt.requiresRepair)
+            }
+            if ins.Damages == nil || ins.Photos == nil {
+                t.Error("damages and photos must be empty lists, not null")
+            }
+        })
+    }
+}
+
+func TestValidateInspection(t *testing.T) {
+    checklist := func(items ...string) []dtos.InspectionChecklistItem {
+        out := make([]dtos.InspectionChecklistItem, len(items))
+        for i, item := range items {
+            out[i] = dtos.InspectionChecklistItem{Item: item, Passed: true}
+        }
+        return out
+    }
+    full := checklist(dtos.InspectionChecklist...)
+    tests := []struct {
+        name   string
+        in     dtos.InspectionRequest
+        fields []string
+    }{
+        {name: "valid", in: dtos.InspectionRequest{Odometer: 36120, Checklist: full,
+            Damages: []dtos.InspectionDamage{{Area: "hood", Description: "chip", Severity: dtos.DamageMinor, RepairCost: 50}}}},
+        {name: "checklist items are case-insensitive", in: dtos.InspectionRequest{Odometer: 1,
+            Checklist: checklist("Exterior", "INTERIOR", "tires", "lights", "windshield", "keys", "documents")}},
+        {name: "missing odometer and checklist items", in: dtos.InspectionRequest{InspectorID: "inspector", Checklist: checklist("exterior")},
+            fields: []string{"inspector_id", "odometer", "checklist"}},
+        {name: "bad damage", in: dtos.InspectionRequest{Odometer: 36120, Checklist: full,
+            Damages: []dtos.InspectionDamage{{Area: " ", Description: "scratch", Severity: "CRITICAL", RepairCost: -1}}},
+            fields: []string{"damages[0].area", "damages[0].severity", "damages[0].repair_cost"}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            err := validateInspection(&tt.in)
+            if len(tt.fields) == 0 {
+                if err != nil {
+                    t.Fatalf("validateInspection = %v, want nil", err)
+                }
+                return
+            }
+            errs, ok := err.(validation.Errors)
+            if !ok || len(errs) != len(tt.fields) {
+                t.Fatalf("validateInspection = %v, want errors on %v", err, tt.fields)
+            }
+            for i, f := range tt.fields {
+                if errs[i].Field != f {
+                    t.Errorf("error %d on %q, want %q", i, errs[i].Field, f)
+                }
+            }
+        })
+    }
+}
+
+// A terminated lease settles its deposit once: the quote only offsets what
+// the inspection left for refund, and damage beyond the deposit is owed.
+func TestBuildTerminationQuoteAfterInspection(t *testing.T) {
+    now := time.Date(2026, 6, 15, 10, 0, 0, 0, time.UTC)
+    schedule := []dtos.LeasePayment{
+        {DueDate: day(2026, 6, 1), Amount: 300, PaidAmount: 100, Status: dtos.LeasePaymentOverdue, Kind: dtos.LeasePaymentInstallment},
+        {DueDate: day(2026, 7, 1), Amount: 300, Status: dtos.LeasePaymentPending, Kind: dtos.LeasePaymentInstallment},
+        {DueDate: day(2026, 8, 1), Amount: 300, Status: dtos.LeasePaymentPending, Kind: dtos.LeasePaymentInstallment},
+    }
+    calc := pricing.NewCalculator(pricing.DefaultConfig())
+    tests := []struct {
+        name                                   string
+        deposit                                float64
+        damage                                 float64
+        quoteDeposit, damageDue, offset, due, refund float64
+    }{
+        // unpaid 200 + fee 300 (50% of 600 remaining) = 500 owed
+        {name: "no damage", deposit: 1500, quoteDeposit: 1500, offset: 500, due: 0, refund: 1000},
+        {name: "damage within the deposit", deposit: 1500, damage: 1200, quoteDeposit: 300, offset: 300, due: 200, refund: 0},
+        {name: "damage beyond the deposit", deposit: 1000, damage: 1400, quoteDeposit: 0, damageDue: 400, offset: 0, due: 900, refund: 0},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            l := &dtos.Lease{ID: "lease-1", Status: dtos.LeaseStatusActive, Deposit: tt.deposit}
+            req := &dtos.InspectionRequest{Odometer: 20000}
+            if tt.damage > 0 {
+                req.Damages = []dtos.InspectionDamage{{Area: "door", Description: "dent", Severity: dtos.DamageModerate, RepairCost: tt.damage}}
+            }
+            ins := buildInspection(l, req)
+            q := buildTerminationQuote(l, ins, schedule, calc, now)
+            got := map[string][2]float64{
+                "deposit":        {q.Deposit, tt.quoteDeposit},
+                "damage_amount":  {q.DamageAmount, tt.damageDue},
+                "deposit_offset": {q.DepositOffset, tt.offset},
+                "amount_due":     {q.AmountDue, tt.due},
+                "deposit_refund": {q.DepositRefund, tt.refund},
+            }
+            for field, v := range got {
+                if v[0] != v[1] {
+                    t.Errorf("%s = %.2f, want %.2f", field, v[0], v[1])
+                }
+            }
+            if used := ins.DepositApplied + q.DepositOffset + q.DepositRefund; used != tt.deposit {
+                t.Errorf("deposit used %.2f in total, want exactly %.2f", used, tt.deposit)
+            }
+        })
+    }
+}
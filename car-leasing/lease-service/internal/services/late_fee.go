@@ -0,0 +1,129 @@
+package services
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+    "go.uber.org/zap"
+    "leaseCar/lease-service/internal/dtos"
+    "leaseCar/lease-service/internal/pricing"
+    "leaseCar/utils/logger"
+)
+
+// assessLateFees prices a late fee for every overdue item and writes it as
+// a LATE_FEE line item linked to that item. Fees that accrue (daily) are
+// updated in place each pass until they reach the cap or the item is paid.
+func (s *LeaseScheduler) assessLateFees(ctx context.Context, today time.Time) (int, error) {
+    n := 0
+    afterID := ""
+    for {
+        items, err := s.payments.ListOverdueChargeable(ctx, afterID, schedulerBatchSize)
+        if err != nil {
+            return n, err
+        }
+        for _, item := range items {
+            fee := buildLateFee(&item, s.pricing, today)
+            if fee == nil {
+                continue
+            }
+            id, err := s.payments.UpsertLateFee(ctx, fee)
+            if err != nil {
+                logger.Warn("late fee failed", zap.String("lease_payment_id", item.ID), zap.Error(err))
+                continue
+            }
+            if id == "" {
+                continue
+            }
+            n++
+            s.publishLateFee(ctx, id, fee, daysBetween(dateOnly(item.DueDate), today))
+        }
+        if len(items) < schedulerBatchSize {
+            return n, nil
+        }
+        afterID = items[len(items)-1].ID
+    }
+}
+
+// buildLateFee returns the late fee for an overdue item as of today, or nil
+// while it is still within the grace period. The fee is due the day it is
+// assessed; a percent fee is a share of the item's full amount, so partial
+// payments do not change it once assessed.
+func buildLateFee(item *dtos.LeasePayment, calc *pricing.Calculator, today time.Time) *dtos.LeasePayment {
+    daysLate := daysBetween(dateOnly(item.DueDate), today)
+    amount := calc.LateFee(item.Amount, daysLate)
+    if amount <= 0 {
+        return nil
+    }
+    related := item.ID
+    return &dtos.LeasePayment{
+        LeaseID:          item.LeaseID,
+        DueDate:          today,
+        Amount:           amount,
+        Status:           dtos.LeasePaymentPending,
+        Kind:             dtos.LeasePaymentLateFee,
+        Description:      fmt.Sprintf("Late fee for payment #%d (%d days late)", item.PaymentNumber, daysLate),
+        RelatedPaymentID: &related,
+    }
+}
+
+func (s *LeaseScheduler) publishLateFee(ctx context.Context, id string, fee *dtos.LeasePayment, daysLate int) {
+    b, _ := json.Marshal(dtos.LateFeeAssessedEvent{
+        Event:            "lease.late_fee_assessed",
+        LeaseID:          fee.LeaseID,
+        LeasePaymentID:   id,
+        RelatedPaymentID: *fee.RelatedPaymentID,
+        Amount:           fee.Amount,
+        DaysLate:         daysLate,
+        AssessedAt:       time.Now(),
+    })
+    if err := s.redisClient.Publish(ctx, "leases", string(b)); err != nil {
+        logger.Error("failed to publish lease event")
+    }
+}
+
+// Balance reports what a lease owes today: unpaid items already due
+// (installments, charges and late fees) plus the amount still scheduled.
+func (s *LeaseService) Balance(ctx context.Context, id string) (*dtos.LeaseBalance, error) {
+    if _, err := s.repo.GetByID(ctx, id); errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrLeaseNotFound
+    } else if err != nil {
+        return nil, err
+    }
+    items, err := s.payments.ListByLease(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+    return buildBalance(id, items, time.Now()), nil
+}
+
+func buildBalance(leaseID string, items []dtos.LeasePayment, now time.Time) *dtos.LeaseBalance {
+    today := dateOnly(now)
+    b := &dtos.LeaseBalance{LeaseID: leaseID, AsOf: today, Items: []dtos.LeasePayment{}}
+    for _, p := range items {
+        if p.Status != dtos.LeasePaymentPending && p.Status != dtos.LeasePaymentOverdue {
+            continue
+        }
+        owed := p.Amount - p.PaidAmount
+        if p.DueDate.After(today) {
+            b.UpcomingAmount += owed
+            continue
+        }
+        b.AmountDue += owed
+        if p.Status == dtos.LeasePaymentOverdue {
+            b.OverdueAmount += owed
+        }
+        if p.Kind == dtos.LeasePaymentLateFee {
+            b.LateFees += owed
+        }
+        b.Items = append(b.Items, p)
+    }
+    b.AmountDue = roundCents(b.AmountDue)
+    b.OverdueAmount = roundCents(b.OverdueAmount)
+    b.LateFees = roundCents(b.LateFees)
+    b.UpcomingAmount = roundCents(b.UpcomingAmount)
+    return b
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+    "testing"
+    "time"
+
+    "leaseCar/lease-service/internal/dtos"
+    "leaseCar/lease-service/internal/pricing"
+)
+
+func TestBuildBalance(t *testing.T) {
+    now := time.Date(2026, 5, 10, 15, 0, 0, 0, time.UTC)
+    items := []dtos.LeasePayment{
+        {PaymentNumber: 1, DueDate: day(2026, 4, 1), Amount: 300, PaidAmount: 300, Status: dtos.LeasePaymentPaid, Kind: dtos.LeasePaymentInstallment},
+        {PaymentNumber: 2, DueDate: day(2026, 4, 1), Amount: 300, PaidAmount: 100, Status: dtos.LeasePaymentOverdue, Kind: dtos.LeasePaymentInstallment},
+        {DueDate: day(2026, 5, 5), Amount: 35, Status: dtos.LeasePaymentPending, Kind: dtos.LeasePaymentLateFee},
+        {PaymentNumber: 3, DueDate: day(2026, 5, 10), Amount: 300, Status: dtos.LeasePaymentPending, Kind: dtos.LeasePaymentInstallment},
+        {PaymentNumber: 4, DueDate: day(2026, 6, 1), Amount: 300, PaidAmount: 50, Status: dtos.LeasePaymentPending, Kind: dtos.LeasePaymentInstallment},
+        {PaymentNumber: 5, DueDate: day(2026, 5, 1), Amount: 300, Status: dtos.LeasePaymentCancelled, Kind: dtos.LeasePaymentInstallment},
+    }
+
+    b := buildBalance("lease-1", items, now)
+    if !b.AsOf.Equal(day(2026, 5, 10)) {
+        t.Errorf("as_of = %s, want 2026-05-10", b.AsOf)
+    }
+    // overdue remainder 200, late fee 35 and today's installment 300
+    want := map[string][2]float64{
+        "amount_due":      {b.AmountDue, 535},
+        "overdue_amount":  {b.OverdueAmount, 200},
+        "late_fees":       {b.LateFees, 35},
+        "upcoming_amount": {b.UpcomingAmount, 250},
+    }
+    for field, v := range want {
+        if v[0] != v[1] {
+            t.Errorf("%s = %.2f, want %.2f", field, v[0], v[1])
+        }
+    }
+    if len(b.Items) != 3 {
+        t.Fatalf("got %d items due, want 3: %+v", len(b.Items), b.Items)
+    }
+}
+
+func TestBuildBalanceNothingOwed(t *testing.T) {
+    items := []dtos.LeasePayment{
+        {DueDate: day(2026, 4, 1), Amount: 300, PaidAmount: 300, Status: dtos.LeasePaymentPaid},
+    }
+    b := buildBalance("lease-1", items, day(2026, 5, 1))
+    if b.AmountDue != 0 || b.UpcomingAmount != 0 || b.Items == nil || len(b.Items) != 0 {
+        t.Fatalf("got %+v, want an empty balance with an empty item list", b)
+    }
+}
+
+func TestBuildLateFee(t *testing.T) {
+    conf := pricing.DefaultConfig()
+    conf.LateFeeType = pricing.LateFeePercent
+    calc := pricing.NewCalculator(conf)
+    item := dtos.LeasePayment{ID: "item-1", LeaseID: "lease-1", PaymentNumber: 2, DueDate: day(2026, 4, 1),
+        Amount: 300, PaidAmount: 100, Status: dtos.LeasePaymentOverdue, Kind: dtos.LeasePaymentInstallment}
+
+    if fee := buildLateFee(&item, calc, day(2026, 4, 6)); fee != nil {
+        t.Fatalf("fee within the grace period = %+v, want none", fee)
+    }
+    fee := buildLateFee(&item, calc, day(2026, 4, 10))
+    if fee == nil {
+        t.Fatal("no fee after the grace period")
+    }
+    // 5% of the full installment, not of what is left after the partial payment
+    if fee.Amount != 15 {
+        t.Errorf("amount = %.2f, want 15.00", fee.Amount)
+    }
+    if !fee.DueDate.Equal(day(2026, 4, 10)) || fee.Kind != dtos.LeasePaymentLateFee || fee.Status != dtos.LeasePaymentPending {
+        t.Errorf("got %+v, want a PENDING LATE_FEE due 2026-04-10", fee)
+    }
+    if fee.RelatedPaymentID == nil || *fee.RelatedPaymentID != "item-1" || fee.LeaseID != "lease-1" {
+        t.Errorf("fee not linked to the overdue item: %+v", fee)
+    }
+}
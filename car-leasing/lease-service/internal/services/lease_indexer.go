@@ -0,0 +1,138 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "sync/atomic"
+    "time"
+
+    "go.uber.org/zap"
+    "leaseCar/lease-service/internal/adapters"
+    "leaseCar/lease-service/internal/dtos"
+    "leaseCar/lease-service/internal/repositories"
+    "leaseCar/utils/logger"
+)
+
+const indexBatchSize = 200
+
+var ErrReindexRunning = errors.New("reindex already running")
+
+// LeaseIndexer keeps the Meilisearch "leases" index in step with Postgres.
+// Leases are picked up from the table whenever their indexed_at lags
+// updated_at, so a failed push is simply retried on the next pass.
+type LeaseIndexer struct {
+    repo       *repositories.LeaseRepository
+    meili      *adapters.MeiliAdapter
+    interval   time.Duration
+    wake       chan struct{}
+    reindexing atomic.Bool
+}
+
+func NewLeaseIndexer(r *repositories.LeaseRepository, m *adapters.MeiliAdapter, interval time.Duration) *LeaseIndexer {
+    return &LeaseIndexer{repo: r, meili: m, interval: interval, wake: make(chan struct{}, 1)}
+}
+
+// Notify asks the indexer to sync as soon as possible instead of waiting for
+// the next tick. It never blocks.
+func (i *LeaseIndexer) Notify() {
+    select {
+    case i.wake <- struct{}{}:
+    default:
+    }
+}
+
+// Run syncs pending leases until ctx is cancelled.
+func (i *LeaseIndexer) Run(ctx context.Context) {
+    ticker := time.NewTicker(i.interval)
+    defer ticker.Stop()
+    for {
+        if _, err := i.SyncPending(ctx); err != nil {
+            logger.Warn("lease index sync failed", zap.Error(err))
+        }
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        case <-i.wake:
+        }
+    }
+}
+
+// SyncPending pushes every lease with a missing or stale document. A batch
+// that fails to push is logged and skipped so the rest of the pass still
+// goes through; its leases stay pending and are retried on the next pass.
+// The returned error joins the errors of all failed batches.
+func (i *LeaseIndexer) SyncPending(ctx context.Context) (int, error) {
+    synced := 0
+    var failed []error
+    var after *dtos.Lease
+    for {
+        leases, err := i.repo.ListUnindexed(ctx, after, indexBatchSize)
+        if err != nil {
+            return synced, errors.Join(append(failed, err)...)
+        }
+        if len(leases) == 0 {
+            return synced, errors.Join(failed...)
+        }
+        if err := i.push(ctx, leases); err != nil {
+            logger.Warn("lease index batch failed", zap.Error(err),
+                zap.String("first_lease_id", leases[0].ID), zap.Int("leases", len(leases)))
+            failed = append(failed, err)
+        } else {
+            synced += len(leases)
+        }
+        if len(leases) < indexBatchSize || ctx.Err() != nil {
+            return synced, errors.Join(append(failed, ctx.Err())...)
+        }
+        after = &leases[len(leases)-1]
+    }
+}
+
+// StartReindex rebuilds the whole index from the leases table in the
+// background. Only one rebuild runs at a time.
+func (i *LeaseIndexer) StartReindex() error {
+    if !i.reindexing.CompareAndSwap(false, true) {
+        return ErrReindexRunning
+    }
+    go func() {
+        defer i.reindexing.Store(false)
+        n, err := i.reindex(context.Background())
+        if err != nil {
+            logger.Error("lease reindex failed", zap.Error(err), zap.Int("leases", n))
+            return
+        }
+        logger.Info("lease reindex finished", zap.Int("leases", n))
+    }()
+    return nil
+}
+
+func (i *LeaseIndexer) reindex(ctx context.Context) (int, error) {
+    if err := i.meili.ConfigureIndex(ctx, "leases", LeaseFilterableAttributes, LeaseSortableAttributes); err != nil {
+        return 0, err
+    }
+    total, after := 0, ""
+    for {
+        leases, err := i.repo.ListAfter(ctx, after, indexBatchSize)
+        if err != nil || len(leases) == 0 {
+            return total, err
+        }
+        if err := i.push(ctx, leases); err != nil {
+            return total, err
+        }
+        total += len(leases)
+        after = leases[len(leases)-1].ID
+    }
+}
+
+func (i *LeaseIndexer) push(ctx context.Context, leases []dtos.Lease) error {
+    docs := make([]dtos.LeaseDocument, len(leases))
+    for n := range leases {
+        docs[n] = dtos.NewLeaseDocument(&leases[n])
+    }
+    // IndexLease waits for the Meili task, so leases whose task failed stay
+    // unindexed and are pushed again on the next pass
+    if err := i.meili.IndexLease(ctx, "leases", docs); err != nil {
+        return err
+    }
+    return i.repo.MarkIndexed(ctx, leases)
+}
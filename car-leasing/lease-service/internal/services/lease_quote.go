@@ -0,0 +1,58 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "fmt"
+
+    "github.com/jackc/pgx/v5"
+    "leaseCar/lease-service/internal/dtos"
+    "leaseCar/lease-service/internal/pricing"
+    "leaseCar/lease-service/internal/validation"
+)
+
+// Quote prices each requested option for a vehicle without persisting
+// anything. It goes through the same pricing path as Create, so a lease
+// created with a quoted option's dates and deposit gets the quoted payment.
+func (s *LeaseService) Quote(ctx context.Context, in *dtos.LeaseQuoteRequest) (*dtos.LeaseQuoteResponse, error) {
+    if err := in.Validate(); err != nil {
+        return nil, err
+    }
+    vehicle, err := s.vehicles.GetByID(ctx, in.VehicleID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrVehicleNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    out := &dtos.LeaseQuoteResponse{VehicleID: vehicle.ID, Quotes: make([]dtos.LeaseQuote, 0, len(in.Options))}
+    var errs validation.Errors
+    for i, o := range in.Options {
+        end := o.EndDate
+        if o.TermMonths > 0 {
+            end = in.StartDate.AddDate(0, o.TermMonths, 0)
+        }
+        quote, err := s.price(vehicle, in.StartDate, end, o.Deposit)
+        switch {
+        case errors.Is(err, pricing.ErrInvalidTerm):
+            errs.Add(fmt.Sprintf("options[%d].term_months", i), err.Error())
+            continue
+        case errors.Is(err, pricing.ErrInvalidDeposit):
+            errs.Add(fmt.Sprintf("options[%d].deposit_paid", i), err.Error())
+            continue
+        case err != nil:
+            return nil, err
+        }
+        out.Quotes = append(out.Quotes, dtos.LeaseQuote{
+            StartDate: dateOnly(in.StartDate),
+            EndDate:   dateOnly(end),
+            Pricing:   quote,
+            Schedule:  BuildSchedule(in.StartDate, end, quote.MonthlyPayment),
+        })
+    }
+    if err := errs.Err(); err != nil {
+        return nil, err
+    }
+    return out, nil
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+    "context"
+    "errors"
+
+    "github.com/jackc/pgx/v5"
+    "leaseCar/lease-service/internal/dtos"
+    "leaseCar/lease-service/internal/repositories"
+)
+
+const (
+    ActionExtend = "extend"
+    ActionRenew  = "renew"
+)
+
+var (
+    ErrInvalidExtension = errors.New("end_date must be after the lease's current end_date")
+    ErrAlreadyRenewed   = errors.New("lease already has an open renewal")
+    ErrDepositSettled   = errors.New("lease deposit has already been settled or carried over")
+)
+
+// Extend pushes out the end date of an active lease. The extra period is
+// billed at the current monthly payment and appended to the schedule.
+func (s *LeaseService) Extend(ctx context.Context, id string, in *dtos.LeaseExtendRequest) (*dtos.LeaseExtension, error) {
+    l, err := s.repo.GetByID(ctx, id)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrLeaseNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    if l.Status != dtos.LeaseStatusActive {
+        return nil, &InvalidTransitionError{Action: ActionExtend, From: l.Status}
+    }
+    newEnd := dateOnly(in.EndDate)
+    if !newEnd.After(dateOnly(l.EndDate)) {
+        return nil, ErrInvalidExtension
+    }
+
+    schedule := BuildSchedule(l.EndDate, newEnd, l.Monthly)
+    ext, err := s.repo.Extend(ctx, l, newEnd, schedule, in.Reason)
+    var overlap *repositories.OverlapError
+    switch {
+    case errors.As(err, &overlap):
+        return nil, &LeaseConflictError{LeaseID: overlap.LeaseID}
+    case errors.Is(err, pgx.ErrNoRows):
+        return nil, ErrLeaseStatusChanged
+    case err != nil:
+        return nil, err
+    }
+    s.indexer.Notify()
+    return ext, nil
+}
+
+// Renew creates a successor lease for the same user and vehicle starting
+// when the current lease ends. The successor is priced like any new lease,
+// carries the deposit still held by the current lease over and starts in
+// DRAFT. The carried-over amount is recorded on the current lease in the
+// same transaction so it is not refunded twice; rejecting or cancelling the
+// renewal hands it back.
+func (s *LeaseService) Renew(ctx context.Context, id string, in *dtos.LeaseRenewRequest) (string, error) {
+    l, err := s.repo.GetByID(ctx, id)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return "", ErrLeaseNotFound
+    }
+    if err != nil {
+        return "", err
+    }
+    if l.Status != dtos.LeaseStatusActive {
+        return "", &InvalidTransitionError{Action: ActionRenew, From: l.Status}
+    }
+    if successor, err := s.repo.FindSuccessor(ctx, id); err != nil {
+        return "", err
+    } else if successor != "" {
+        return "", ErrAlreadyRenewed
+    }
+
+    renewal, err := s.Create(ctx, renewalRequest(l, in))
+    if errors.Is(err, pgx.ErrNoRows) {
+        return "", ErrDepositSettled
+    }
+    return renewal, err
+}
+
+// renewalRequest builds the create request for the successor of l. The
+// deposit is always set explicitly, so a lease without a deposit is not
+// given the vehicle's default one on renewal.
+func renewalRequest(l *dtos.Lease, in *dtos.LeaseRenewRequest) *dtos.LeaseCreateRequest {
+    deposit := l.DepositHeld()
+    next := &dtos.LeaseCreateRequest{
+        UserID:          l.UserID,
+        VehicleID:       l.VehicleID,
+        StartDate:       l.EndDate,
+        EndDate:         in.EndDate,
+        Deposit:         &deposit,
+        MileageLimit:    in.MileageLimit,
+        PreviousLeaseID: l.ID,
+    }
+    if next.EndDate.IsZero() {
+        next.EndDate = next.StartDate.AddDate(0, 12, 0)
+    }
+    if next.MileageLimit == 0 {
+        next.MileageLimit = l.MileageLimit
+    }
+    return next
+}
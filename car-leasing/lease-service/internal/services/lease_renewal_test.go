@@ -0,0 +1,51 @@
+package services
+
+import (
+    "testing"
+    "time"
+
+    "leaseCar/lease-service/internal/dtos"
+)
+
+func TestRenewalRequestDeposit(t *testing.T) {
+    tests := []struct {
+        name                 string
+        deposit, transferred float64
+        want                 float64
+    }{
+        {name: "full deposit held", deposit: 1500, want: 1500},
+        {name: "no deposit", deposit: 0, want: 0},
+        {name: "partly carried over", deposit: 1500, transferred: 500, want: 1000},
+        {name: "fully carried over", deposit: 1500, transferred: 1500, want: 0},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            l := &dtos.Lease{ID: "lease-1", EndDate: day(2027, 1, 1), Deposit: tt.deposit, DepositTransferred: tt.transferred}
+            next := renewalRequest(l, &dtos.LeaseRenewRequest{})
+            // nil would make Create fall back to the vehicle's deposit_amount
+            if next.Deposit == nil {
+                t.Fatal("deposit not set")
+            }
+            if *next.Deposit != tt.want {
+                t.Fatalf("deposit = %.2f, want %.2f", *next.Deposit, tt.want)
+            }
+        })
+    }
+}
+
+func TestRenewalRequestDefaults(t *testing.T) {
+    l := &dtos.Lease{ID: "lease-1", UserID: "user-1", VehicleID: "vehicle-1", EndDate: day(2027, 1, 1), MileageLimit: 15000}
+
+    next := renewalRequest(l, &dtos.LeaseRenewRequest{})
+    if !next.StartDate.Equal(l.EndDate) || !next.EndDate.Equal(day(2028, 1, 1)) {
+        t.Errorf("period = %s..%s, want 2027-01-01..2028-01-01", next.StartDate.Format(time.DateOnly), next.EndDate.Format(time.DateOnly))
+    }
+    if next.MileageLimit != 15000 || next.PreviousLeaseID != "lease-1" || next.UserID != "user-1" || next.VehicleID != "vehicle-1" {
+        t.Errorf("got %+v", next)
+    }
+
+    next = renewalRequest(l, &dtos.LeaseRenewRequest{EndDate: day(2027, 7, 1), MileageLimit: 8000})
+    if !next.EndDate.Equal(day(2027, 7, 1)) || next.MileageLimit != 8000 {
+        t.Errorf("requested terms not kept: %+v", next)
+    }
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "os"
+    "strconv"
+    "time"
+
+    "go.uber.org/zap"
+    "leaseCar/lease-service/internal/dtos"
+    "leaseCar/lease-service/internal/pricing"
+    "leaseCar/lease-service/internal/repositories"
+    "leaseCar/utils/logger"
+    redisutil "leaseCar/utils/redis"
+)
+
+const (
+    schedulerLockKey   = "lease-scheduler:lock"
+    schedulerBatchSize = 200
+)
+
+// unlockScript releases the scheduler lock only if this replica still owns it.
+const unlockScript = `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+    return redis.call("DEL", KEYS[1])
+end
+return 0`
+
+// extendLockScript pushes out the lock's expiry only if this replica still
+// owns it.
+const extendLockScript = `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+    return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`
+
+// SchedulerResult counts what a single scheduler pass changed.
+type SchedulerResult struct {
+    Activated int
+    Completed int
+    Overdue   int
+    LateFees  int
+}
+
+// LeaseScheduler applies date-driven changes: APPROVED leases start on their
+// start_date, ACTIVE leases complete on their end_date, unpaid items turn
+// OVERDUE after their due_date and overdue items accrue late fees. A Redis
+// lock keeps replicas from running a pass at the same time; lease
+// transitions are additionally guarded on the current status, overdue
+// marking skips locked rows and late fees are upserted per item, so a lost
+// lock cannot cause double changes. The lock is extended while a pass runs,
+// and a pass that loses it stops early.
+type LeaseScheduler struct {
+    leases      *LeaseService
+    repo        *repositories.LeaseRepository
+    payments    *repositories.LeasePaymentRepository
+    pricing     *pricing.Calculator
+    redisClient *redisutil.Client
+    interval    time.Duration
+    owner       string
+}
+
+func NewLeaseScheduler(svc *LeaseService, r *repositories.LeaseRepository, p *repositories.LeasePaymentRepository, calc *pricing.Calculator, rc *redisutil.Client, interval time.Duration) *LeaseScheduler {
+    host, _ := os.Hostname()
+    owner := host + ":" + strconv.Itoa(os.Getpid()) + ":" + strconv.FormatInt(time.Now().UnixNano(), 36)
+    return &LeaseScheduler{leases: svc, repo: r, payments: p, pricing: calc, redisClient: rc, interval: interval, owner: owner}
+}
+
+// Run executes a pass every interval until ctx is cancelled.
+func (s *LeaseScheduler) Run(ctx context.Context) {
+    ticker := time.NewTicker(s.interval)
+    defer ticker.Stop()
+    for {
+        if _, err := s.RunOnce(ctx); err != nil {
+            logger.Warn("lease scheduler pass failed", zap.Error(err))
+        }
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+// RunOnce performs one pass if this replica gets the lock. It returns a zero
+// result when another replica holds it.
+func (s *LeaseScheduler) RunOnce(ctx context.Context) (SchedulerResult, error) {
+    var res SchedulerResult
+    ok, err := s.redisClient.SetNX(ctx, schedulerLockKey, s.owner, s.lockTTL())
+    if err != nil || !ok {
+        return res, err
+    }
+    defer func() {
+        if _, err := s.redisClient.Eval(context.Background(), unlockScript, []string{schedulerLockKey}, s.owner); err != nil {
+            logger.Warn("failed to release lease scheduler lock", zap.Error(err))
+        }
+    }()
+    ctx, cancel := context.WithCancel(ctx)
+    defer cancel()
+    go s.keepLock(ctx, cancel)
+
+    today := dateOnly(time.Now())
+    if res.Activated, err = s.advance(ctx, today, s.repo.ListStartingBy, ActionActivate); err != nil {
+        return res, err
+    }
+    if res.Completed, err = s.advance(ctx, today, s.repo.ListEndingBy, ActionComplete); err != nil {
+        return res, err
+    }
+    if res.Overdue, err = s.markOverdue(ctx, today); err != nil {
+        return res, err
+    }
+    res.LateFees, err = s.assessLateFees(ctx, today)
+    return res, err
+}
+
+func (s *LeaseScheduler) lockTTL() time.Duration {
+    return 2 * s.interval
+}
+
+// keepLock extends the scheduler lock every interval, half its TTL, until
+// ctx is done. If the lock cannot be extended it cancels the pass, since
+// another replica may take over.
+func (s *LeaseScheduler) keepLock(ctx context.Context, cancel context.CancelFunc) {
+    ticker := time.NewTicker(s.interval)
+    defer ticker.Stop()
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+        extended, err := s.redisClient.Eval(ctx, extendLockScript, []string{schedulerLockKey}, s.owner, s.lockTTL().Milliseconds())
+        if n, _ := extended.(int64); err != nil || n == 0 {
+            if ctx.Err() == nil {
+                logger.Warn("lost lease scheduler lock, stopping pass", zap.Error(err))
+            }
+            cancel()
+            return
+        }
+    }
+}
+
+// advance applies action to every lease list returns for today. Each lease
+// goes through LeaseService.Transition, so schedules, vehicle availability,
+// indexing and lease.status_changed events behave as for manual calls. A
+// lease that fails is logged and retried on the next pass; pages are walked
+// by keyset so failures can't hold back the leases behind them.
+func (s *LeaseScheduler) advance(ctx context.Context, today time.Time, list func(context.Context, time.Time, *dtos.Lease, int) ([]dtos.Lease, error), action string) (int, error) {
+    n := 0
+    var after *dtos.Lease
+    for {
+        leases, err := list(ctx, today, after, schedulerBatchSize)
+        if err != nil {
+            return n, err
+        }
+        for _, l := range leases {
+            if ctx.Err() != nil {
+                return n, ctx.Err()
+            }
+            _, err := s.leases.Transition(ctx, l.ID, action, "scheduled")
+            if errors.Is(err, ErrLeaseStatusChanged) {
+                continue
+            }
+            if err != nil {
+                logger.Warn("scheduled lease transition failed", zap.String("action", action), zap.String("lease_id", l.ID), zap.Error(err))
+                continue
+            }
+            n++
+        }
+        if len(leases) < schedulerBatchSize {
+            return n, nil
+        }
+        after = &leases[len(leases)-1]
+    }
+}
+
+func (s *LeaseScheduler) markOverdue(ctx context.Context, today time.Time) (int, error) {
+    payments, err := s.payments.MarkOverdue(ctx, today, schedulerBatchSize)
+    if err != nil {
+        return 0, err
+    }
+    now := time.Now()
+    for _, p := range payments {
+        b, _ := json.Marshal(dtos.LeasePaymentOverdueEvent{
+            Event:          "lease.payment_overdue",
+            LeaseID:        p.LeaseID,
+            LeasePaymentID: p.ID,
+            PaymentNumber:  p.PaymentNumber,
+            Kind:           p.Kind,
+            DueDate:        p.DueDate,
+            Amount:         p.Amount,
+            MarkedAt:       now,
+        })
+        if err := s.redisClient.Publish(ctx, "leases", string(b)); err != nil {
+            logger.Error("failed to publish lease event")
+        }
+    }
+    return len(payments), nil
+}
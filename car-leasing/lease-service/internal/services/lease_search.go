@@ -0,0 +1,102 @@
+package services
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+
+    meili "github.com/meilisearch/meilisearch-go"
+    "leaseCar/lease-service/internal/dtos"
+)
+
+// Attributes configured on the "leases" index. Dates are indexed as unix
+// timestamps (<field>_ts) because Meilisearch only range-filters numbers.
+var (
+    LeaseFilterableAttributes = []string{"status", "user_id", "vehicle_id", "start_date_ts", "end_date_ts", "monthly_payment"}
+    LeaseSortableAttributes   = []string{"start_date_ts", "end_date_ts", "monthly_payment", "created_at_ts"}
+)
+
+// leaseSortFields maps public sort names to indexed attributes.
+var leaseSortFields = map[string]string{
+    "start_date":      "start_date_ts",
+    "end_date":        "end_date_ts",
+    "monthly_payment": "monthly_payment",
+    "created_at":      "created_at_ts",
+}
+
+var leaseFacetFields = map[string]bool{"status": true, "user_id": true, "vehicle_id": true}
+
+var ErrInvalidSearch = errors.New("invalid search parameters")
+
+// buildLeaseSearch translates a search request into Meilisearch filter,
+// sort, facet and pagination settings, normalizing limit and offset in place.
+func buildLeaseSearch(in *dtos.LeaseSearchRequest) (*meili.SearchRequest, error) {
+    if in.Limit <= 0 || in.Limit > 100 {
+        in.Limit = 20
+    }
+    if in.Offset < 0 {
+        in.Offset = 0
+    }
+
+    var filters []string
+    if len(in.Status) > 0 {
+        quoted := make([]string, len(in.Status))
+        for i, st := range in.Status {
+            quoted[i] = quoteFilter(strings.ToUpper(st))
+        }
+        filters = append(filters, fmt.Sprintf("status IN [%s]", strings.Join(quoted, ", ")))
+    }
+    if in.UserID != "" {
+        filters = append(filters, "user_id = "+quoteFilter(in.UserID))
+    }
+    if in.VehicleID != "" {
+        filters = append(filters, "vehicle_id = "+quoteFilter(in.VehicleID))
+    }
+    if in.StartFrom != nil {
+        filters = append(filters, fmt.Sprintf("start_date_ts >= %d", in.StartFrom.Unix()))
+    }
+    if in.StartTo != nil {
+        filters = append(filters, fmt.Sprintf("start_date_ts <= %d", in.StartTo.Unix()))
+    }
+    if in.EndFrom != nil {
+        filters = append(filters, fmt.Sprintf("end_date_ts >= %d", in.EndFrom.Unix()))
+    }
+    if in.EndTo != nil {
+        filters = append(filters, fmt.Sprintf("end_date_ts <= %d", in.EndTo.Unix()))
+    }
+    if in.MinMonthly != nil {
+        filters = append(filters, fmt.Sprintf("monthly_payment >= %f", *in.MinMonthly))
+    }
+    if in.MaxMonthly != nil {
+        filters = append(filters, fmt.Sprintf("monthly_payment <= %f", *in.MaxMonthly))
+    }
+
+    req := &meili.SearchRequest{Limit: int64(in.Limit), Offset: int64(in.Offset)}
+    if len(filters) > 0 {
+        req.Filter = strings.Join(filters, " AND ")
+    }
+
+    if in.Sort != "" {
+        field, dir, _ := strings.Cut(in.Sort, ":")
+        attr, ok := leaseSortFields[field]
+        if dir == "" {
+            dir = "asc"
+        }
+        if !ok || (dir != "asc" && dir != "desc") {
+            return nil, fmt.Errorf("%w: unsupported sort %q", ErrInvalidSearch, in.Sort)
+        }
+        req.Sort = []string{attr + ":" + dir}
+    }
+
+    for _, f := range in.Facets {
+        if !leaseFacetFields[f] {
+            return nil, fmt.Errorf("%w: unsupported facet %q", ErrInvalidSearch, f)
+        }
+    }
+    req.Facets = in.Facets
+    return req, nil
+}
+
+func quoteFilter(v string) string {
+    return `"` + strings.ReplaceAll(v, `"`, `\"`) + `"`
+}
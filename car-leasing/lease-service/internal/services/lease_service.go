@@ -2,55 +2,257 @@ package services
 
 import (
     "context"
+    "encoding/json"
+    "errors"
+    "strings"
     "time"
 
+    "github.com/jackc/pgx/v5"
+    "go.uber.org/zap"
     "leaseCar/lease-service/internal/dtos"
+    "leaseCar/lease-service/internal/pricing"
     "leaseCar/lease-service/internal/repositories"
+    "leaseCar/lease-service/internal/validation"
     "leaseCar/lease-service/internal/adapters"
+    "leaseCar/utils/logger"
+    redisutil "leaseCar/utils/redis"
 )
 
 type LeaseService struct {
     repo *repositories.LeaseRepository
+    payments *repositories.LeasePaymentRepository
+    vehicles *repositories.VehicleRepository
+    pricing *pricing.Calculator
     meili *adapters.MeiliAdapter
+    indexer *LeaseIndexer
+    mileage *MileageService
+    holds *ReservationService
+    credit *CreditService
+    inspections *InspectionService
+    redisClient *redisutil.Client
 }
 
-func NewLeaseService(r *repositories.LeaseRepository, m *adapters.MeiliAdapter) *LeaseService {
-    return &LeaseService{repo: r, meili: m}
+func NewLeaseService(r *repositories.LeaseRepository, p *repositories.LeasePaymentRepository, v *repositories.VehicleRepository, calc *pricing.Calculator, m *adapters.MeiliAdapter, idx *LeaseIndexer, mil *MileageService, h *ReservationService, cr *CreditService, ins *InspectionService, rc *redisutil.Client) *LeaseService {
+    return &LeaseService{repo: r, payments: p, vehicles: v, pricing: calc, meili: m, indexer: idx, mileage: mil, holds: h, credit: cr, inspections: ins, redisClient: rc}
 }
 
 func (s *LeaseService) Create(ctx context.Context, in *dtos.LeaseCreateRequest) (string, error) {
-    id, err := s.repo.Create(ctx, in)
+    if err := in.Validate(); err != nil {
+        return "", err
+    }
+    vehicle, err := s.vehicles.GetByID(ctx, in.VehicleID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return "", ErrVehicleNotFound
+    }
     if err != nil {
         return "", err
     }
+    if err := s.holds.CheckAvailable(ctx, in.VehicleID, in.UserID); err != nil {
+        return "", err
+    }
 
-    // create simple lease payload for indexing
-    lease := map[string]interface{}{
-        "id": id,
-        "user_id": in.UserID,
-        "vehicle_id": in.VehicleID,
-        "start_date": in.StartDate,
-        "end_date": in.EndDate,
-        "monthly_payment": in.Monthly,
+    // monthly payment and total cost are always derived server-side
+    quote, err := s.price(vehicle, in.StartDate, in.EndDate, in.Deposit)
+    if err != nil {
+        return "", err
     }
-    // index to meili (best-effort)
-    go func() {
-        ctx2, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-        defer cancel()
-        _ = s.meili.IndexLease(ctx2, "leases", lease)
-    }()
+    in.Monthly = quote.MonthlyPayment
+    in.Deposit = &quote.Deposit
 
+    id, err := s.repo.Create(ctx, in, quote)
+    var overlap *repositories.OverlapError
+    if errors.As(err, &overlap) {
+        return "", &LeaseConflictError{LeaseID: overlap.LeaseID}
+    }
+    if err != nil {
+        return "", err
+    }
+
+    // the lease now blocks the vehicle; the user's hold is no longer needed
+    if err := s.holds.Release(ctx, in.VehicleID, in.UserID); err != nil && !errors.Is(err, ErrHoldNotFound) {
+        logger.Warn("failed to release vehicle hold", zap.String("vehicle_id", in.VehicleID), zap.Error(err))
+    }
+    s.indexer.Notify()
     return id, nil
 }
 
+// price runs the calculator for a vehicle over [start, end). A nil deposit
+// falls back to the vehicle's configured deposit_amount. total_cost is the
+// deposit plus the installments BuildSchedule bills for the period, so a
+// partial last month is prorated exactly as in the schedule.
+func (s *LeaseService) price(vehicle *dtos.Vehicle, start, end time.Time, deposit *float64) (*dtos.LeasePricing, error) {
+    in := pricing.Input{PricePerMonth: vehicle.PricePerMonth, TermMonths: pricing.TermMonths(start, end)}
+    if deposit != nil {
+        in.Deposit = *deposit
+    } else if vehicle.DepositAmount != nil {
+        in.Deposit = *vehicle.DepositAmount
+    }
+    p, err := s.pricing.Calculate(in)
+    if err != nil {
+        return nil, err
+    }
+    total := p.Deposit
+    for _, item := range BuildSchedule(start, end, p.MonthlyPayment) {
+        total += item.Amount
+    }
+    p.TotalCost = roundCents(total)
+    return p, nil
+}
+
 func (s *LeaseService) GetByID(ctx context.Context, id string) (*dtos.Lease, error) {
-    return s.repo.GetByID(ctx, id)
+    if !validation.IsUUID(id) {
+        return nil, ErrLeaseNotFound
+    }
+    l, err := s.repo.GetByID(ctx, id)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrLeaseNotFound
+    }
+    return l, err
 }
 
-func (s *LeaseService) Search(ctx context.Context, q string, limit int) (interface{}, error) {
-    res, err := s.meili.Search(ctx, "leases", q, limit)
+// ListByUser pages through a user's leases straight from Postgres, newest
+// first.
+func (s *LeaseService) ListByUser(ctx context.Context, in *dtos.LeaseListRequest) (*dtos.LeasePage, error) {
+    if in.Limit <= 0 || in.Limit > 100 {
+        in.Limit = 20
+    }
+    var afterCreatedAt *time.Time
+    var afterID string
+    if in.Cursor != "" {
+        createdAt, id, err := decodeCursor(in.Cursor)
+        if err != nil {
+            return nil, err
+        }
+        afterCreatedAt, afterID = &createdAt, id
+    }
+
+    // fetch one extra row to know whether another page exists
+    leases, err := s.repo.ListByUser(ctx, in.UserID, strings.ToUpper(in.Status), afterCreatedAt, afterID, in.Limit+1)
+    if err != nil {
+        return nil, err
+    }
+    page := &dtos.LeasePage{Leases: leases}
+    if len(leases) > in.Limit {
+        page.Leases = leases[:in.Limit]
+        last := page.Leases[in.Limit-1]
+        page.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+    }
+    return page, nil
+}
+
+func (s *LeaseService) Search(ctx context.Context, in *dtos.LeaseSearchRequest) (*dtos.LeaseSearchResponse, error) {
+    req, err := buildLeaseSearch(in)
+    if err != nil {
+        return nil, err
+    }
+    res, err := s.meili.Search(ctx, "leases", in.Query, req)
     if err != nil {
         return nil, err
     }
-    return res.Hits, nil
+
+    out := &dtos.LeaseSearchResponse{
+        Hits:   res.Hits,
+        Total:  res.EstimatedTotalHits,
+        Limit:  in.Limit,
+        Offset: in.Offset,
+        Facets: res.FacetDistribution,
+    }
+    if out.Hits == nil {
+        out.Hits = []interface{}{}
+    }
+    if next := int64(in.Offset + in.Limit); next < out.Total {
+        n := int(next)
+        out.NextOffset = &n
+    }
+    return out, nil
+}
+
+// Transition applies a lifecycle action to a lease and publishes a
+// lease.status_changed event once the new status is persisted. Submitted
+// leases are credit-scored straight away and may come back APPROVED or
+// REJECTED.
+func (s *LeaseService) Transition(ctx context.Context, id, action, reason string) (*dtos.Lease, error) {
+    if action == ActionTerminate {
+        return s.Terminate(ctx, id, &dtos.TerminateRequest{Reason: reason})
+    }
+
+    current, err := s.repo.GetByID(ctx, id)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrLeaseNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    to, err := nextStatus(current.Status, action)
+    if err != nil {
+        return nil, err
+    }
+
+    var schedule []dtos.LeasePayment
+    switch to {
+    case dtos.LeaseStatusActive:
+        schedule = BuildSchedule(current.StartDate, current.EndDate, current.Monthly)
+    case dtos.LeaseStatusCompleted:
+        // the return inspection must be on file; damage beyond the deposit
+        // and mileage overage become the lease's final billable items
+        damage, err := s.inspections.DamageCharge(ctx, current)
+        if err != nil {
+            return nil, err
+        }
+        if damage != nil {
+            schedule = append(schedule, *damage)
+        }
+        charge, err := s.mileage.OverageCharge(ctx, current)
+        if err != nil {
+            return nil, err
+        }
+        if charge != nil {
+            schedule = append(schedule, *charge)
+        }
+    }
+    updated, err := s.repo.UpdateStatus(ctx, id, current.Status, to, schedule)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrLeaseStatusChanged
+    }
+    var overlap *repositories.OverlapError
+    if errors.As(err, &overlap) {
+        return nil, &LeaseConflictError{LeaseID: overlap.LeaseID}
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    s.indexer.Notify()
+    s.publishStatusChanged(current.Status, updated, reason)
+    if to == dtos.LeaseStatusPendingApproval {
+        return s.autoDecide(ctx, updated), nil
+    }
+    return updated, nil
+}
+
+// GetSchedule returns the installment schedule generated at activation.
+// Leases that were never activated have an empty schedule.
+func (s *LeaseService) GetSchedule(ctx context.Context, id string) ([]dtos.LeasePayment, error) {
+    if _, err := s.repo.GetByID(ctx, id); errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrLeaseNotFound
+    } else if err != nil {
+        return nil, err
+    }
+    return s.payments.ListByLease(ctx, id)
+}
+
+func (s *LeaseService) publishStatusChanged(from string, l *dtos.Lease, reason string) {
+    event := dtos.LeaseStatusChangedEvent{
+        Event:     "lease.status_changed",
+        LeaseID:   l.ID,
+        From:      from,
+        To:        l.Status,
+        Reason:    reason,
+        ChangedAt: time.Now(),
+    }
+    b, _ := json.Marshal(event)
+    if err := s.redisClient.Publish(context.Background(), "leases", string(b)); err != nil {
+        logger.Error("failed to publish lease event")
+    }
 }
@@ -0,0 +1,68 @@
+package services
+
+import (
+    "testing"
+    "time"
+
+    "leaseCar/lease-service/internal/dtos"
+    "leaseCar/lease-service/internal/pricing"
+)
+
+func TestPriceTotalMatchesSchedule(t *testing.T) {
+    s := &LeaseService{pricing: pricing.NewCalculator(pricing.DefaultConfig())}
+    vehicle := &dtos.Vehicle{PricePerMonth: 300}
+    tests := []struct {
+        name       string
+        start, end time.Time
+    }{
+        {name: "whole months", start: day(2026, 1, 1), end: day(2029, 1, 1)},
+        // TermMonths rounds the trailing 14 days up to a month, the
+        // schedule prorates them
+        {name: "partial last month", start: day(2026, 1, 1), end: day(2027, 1, 15)},
+        {name: "partial first and last month", start: day(2026, 1, 15), end: day(2027, 3, 10)},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            deposit := 1000.0
+            p, err := s.price(vehicle, tt.start, tt.end, &deposit)
+            if err != nil {
+                t.Fatal(err)
+            }
+            want := p.Deposit
+            for _, item := range BuildSchedule(tt.start, tt.end, p.MonthlyPayment) {
+                want += item.Amount
+            }
+            if p.TotalCost != roundCents(want) {
+                t.Fatalf("total_cost = %.2f, schedule plus deposit = %.2f", p.TotalCost, roundCents(want))
+            }
+        })
+    }
+}
+
+func TestPriceDeposit(t *testing.T) {
+    s := &LeaseService{pricing: pricing.NewCalculator(pricing.DefaultConfig())}
+    vehicleDeposit := 1500.0
+    zero, own := 0.0, 800.0
+    tests := []struct {
+        name    string
+        vehicle *dtos.Vehicle
+        deposit *float64
+        want    float64
+    }{
+        {name: "vehicle default", vehicle: &dtos.Vehicle{PricePerMonth: 300, DepositAmount: &vehicleDeposit}, want: 1500},
+        {name: "no vehicle default", vehicle: &dtos.Vehicle{PricePerMonth: 300}, want: 0},
+        {name: "explicit zero", vehicle: &dtos.Vehicle{PricePerMonth: 300, DepositAmount: &vehicleDeposit}, deposit: &zero, want: 0},
+        {name: "explicit amount", vehicle: &dtos.Vehicle{PricePerMonth: 300, DepositAmount: &vehicleDeposit}, deposit: &own, want: 800},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            p, err := s.price(tt.vehicle, day(2026, 1, 1), day(2027, 1, 1), tt.deposit)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if p.Deposit != tt.want {
+                t.Fatalf("deposit = %.2f, want %.2f", p.Deposit, tt.want)
+            }
+        })
+    }
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+    "errors"
+    "fmt"
+
+    "leaseCar/lease-service/internal/dtos"
+)
+
+// Lease lifecycle actions exposed as POST /leases/:id/<action>.
+const (
+    ActionSubmit    = "submit"
+    ActionApprove   = "approve"
+    ActionReject    = "reject"
+    ActionActivate  = "activate"
+    ActionComplete  = "complete"
+    ActionTerminate = "terminate"
+    ActionCancel    = "cancel"
+)
+
+var (
+    ErrLeaseNotFound      = errors.New("lease not found")
+    ErrLeaseStatusChanged = errors.New("lease status changed concurrently, retry")
+    ErrVehicleNotFound    = errors.New("vehicle not found")
+)
+
+// InvalidTransitionError is returned when an action is not allowed from the
+// lease's current status.
+type InvalidTransitionError struct {
+    Action string
+    From   string
+}
+
+func (e *InvalidTransitionError) Error() string {
+    return fmt.Sprintf("cannot %s a lease in status %s", e.Action, e.From)
+}
+
+// LeaseConflictError is returned when the vehicle is already booked by
+// another lease for an overlapping period.
+type LeaseConflictError struct {
+    LeaseID string
+}
+
+func (e *LeaseConflictError) Error() string {
+    return "vehicle is already leased for an overlapping period"
+}
+
+type transition struct {
+    from []string
+    to   string
+}
+
+// leaseTransitions is the full lease state machine:
+//
+//    DRAFT -> PENDING_APPROVAL -> APPROVED -> ACTIVE -> COMPLETED
+//      |              |              |          |
+//      +------+-------+              +----------+--> TERMINATED
+//             |       |
+//             v       v
+//     CANCELLED    REJECTED
+var leaseTransitions = map[string]transition{
+    ActionSubmit:    {from: []string{dtos.LeaseStatusDraft}, to: dtos.LeaseStatusPendingApproval},
+    ActionApprove:   {from: []string{dtos.LeaseStatusPendingApproval}, to: dtos.LeaseStatusApproved},
+    ActionReject:    {from: []string{dtos.LeaseStatusPendingApproval}, to: dtos.LeaseStatusRejected},
+    ActionActivate:  {from: []string{dtos.LeaseStatusApproved}, to: dtos.LeaseStatusActive},
+    ActionComplete:  {from: []string{dtos.LeaseStatusActive}, to: dtos.LeaseStatusCompleted},
+    ActionTerminate: {from: []string{dtos.LeaseStatusApproved, dtos.LeaseStatusActive}, to: dtos.LeaseStatusTerminated},
+    ActionCancel:    {from: []string{dtos.LeaseStatusDraft, dtos.LeaseStatusPendingApproval}, to: dtos.LeaseStatusCancelled},
+}
+
+// nextStatus resolves the target status for action, or fails if the action
+// is unknown or not permitted from the current status.
+func nextStatus(current, action string) (string, error) {
+    t, ok := leaseTransitions[action]
+    if !ok {
+        return "", fmt.Errorf("unknown lease action %q", action)
+    }
+    for _, from := range t.from {
+        if from == current {
+            return t.to, nil
+        }
+    }
+    return "", &InvalidTransitionError{Action: action, From: current}
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+    "errors"
+    "testing"
+
+    "leaseCar/lease-service/internal/dtos"
+)
+
+func TestNextStatus(t *testing.T) {
+    tests := []struct {
+        from, action, to string
+    }{
+        {dtos.LeaseStatusDraft, ActionSubmit, dtos.LeaseStatusPendingApproval},
+        {dtos.LeaseStatusPendingApproval, ActionApprove, dtos.LeaseStatusApproved},
+        {dtos.LeaseStatusPendingApproval, ActionReject, dtos.LeaseStatusRejected},
+        {dtos.LeaseStatusApproved, ActionActivate, dtos.LeaseStatusActive},
+        {dtos.LeaseStatusActive, ActionComplete, dtos.LeaseStatusCompleted},
+        {dtos.LeaseStatusApproved, ActionTerminate, dtos.LeaseStatusTerminated},
+        {dtos.LeaseStatusActive, ActionTerminate, dtos.LeaseStatusTerminated},
+        {dtos.LeaseStatusDraft, ActionCancel, dtos.LeaseStatusCancelled},
+        {dtos.LeaseStatusPendingApproval, ActionCancel, dtos.LeaseStatusCancelled},
+    }
+    for _, tt := range tests {
+        to, err := nextStatus(tt.from, tt.action)
+        if err != nil {
+            t.Errorf("%s from %s: %v", tt.action, tt.from, err)
+            continue
+        }
+        if to != tt.to {
+            t.Errorf("%s from %s = %s, want %s", tt.action, tt.from, to, tt.to)
+        }
+    }
+}
+
+func TestNextStatusRejectsIllegalTransitions(t *testing.T) {
+    statuses := []string{dtos.LeaseStatusDraft, dtos.LeaseStatusPendingApproval, dtos.LeaseStatusApproved,
+        dtos.LeaseStatusActive, dtos.LeaseStatusCompleted, dtos.LeaseStatusRejected, dtos.LeaseStatusTerminated,
+        dtos.LeaseStatusCancelled}
+    for action, tr := range leaseTransitions {
+        allowed := map[string]bool{}
+        for _, from := range tr.from {
+            allowed[from] = true
+        }
+        for _, from := range statuses {
+            if allowed[from] {
+                continue
+            }
+            _, err := nextStatus(from, action)
+            var invalid *InvalidTransitionError
+            if !errors.As(err, &invalid) {
+                t.Errorf("%s from %s: got %v, want InvalidTransitionError", action, from, err)
+                continue
+            }
+            if invalid.Action != action || invalid.From != from {
+                t.Errorf("%s from %s: error reports %s from %s", action, from, invalid.Action, invalid.From)
+            }
+        }
+    }
+}
+
+func TestNextStatusUnknownAction(t *testing.T) {
+    _, err := nextStatus(dtos.LeaseStatusDraft, "archive")
+    var invalid *InvalidTransitionError
+    if err == nil || errors.As(err, &invalid) {
+        t.Fatalf("got %v, want an unknown action error", err)
+    }
+}
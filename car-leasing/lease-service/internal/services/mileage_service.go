@@ -0,0 +1,161 @@
+package services
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "math"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+    "leaseCar/lease-service/internal/dtos"
+    "leaseCar/lease-service/internal/pricing"
+    "leaseCar/lease-service/internal/repositories"
+    "leaseCar/utils/logger"
+    redisutil "leaseCar/utils/redis"
+)
+
+var (
+    ErrInvalidReading    = errors.New("reading must be a non-negative odometer value")
+    ErrReadingDecreasing = errors.New("reading is lower than a previously recorded reading")
+)
+
+type MileageService struct {
+    leases      *repositories.LeaseRepository
+    readings    *repositories.OdometerRepository
+    pricing     *pricing.Calculator
+    redisClient *redisutil.Client
+}
+
+func NewMileageService(l *repositories.LeaseRepository, o *repositories.OdometerRepository, calc *pricing.Calculator, rc *redisutil.Client) *MileageService {
+    return &MileageService{leases: l, readings: o, pricing: calc, redisClient: rc}
+}
+
+// RecordReading stores an odometer reading for an active lease and returns
+// the updated projection. A lease.mileage_warning event is published when
+// the lease is on track to exceed its mileage limit.
+func (s *MileageService) RecordReading(ctx context.Context, leaseID string, in *dtos.OdometerReadingRequest) (*dtos.MileageReport, error) {
+    if in.Reading < 0 {
+        return nil, ErrInvalidReading
+    }
+    if in.RecordedAt.IsZero() {
+        in.RecordedAt = time.Now()
+    }
+    l, err := s.activeLease(ctx, leaseID)
+    if err != nil {
+        return nil, err
+    }
+    if _, err := s.readings.Create(ctx, leaseID, in); errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrReadingDecreasing
+    } else if err != nil {
+        return nil, err
+    }
+
+    report, err := s.report(ctx, l)
+    if err != nil {
+        return nil, err
+    }
+    if report.OnTrackToExceed {
+        s.publishWarning(report)
+    }
+    return report, nil
+}
+
+func (s *MileageService) Report(ctx context.Context, leaseID string) (*dtos.MileageReport, error) {
+    l, err := s.leases.GetByID(ctx, leaseID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrLeaseNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    return s.report(ctx, l)
+}
+
+// OverageCharge returns the end-of-lease mileage overage as a billable item,
+// or nil when the lease stayed within its limit or has too few readings to
+// tell.
+func (s *MileageService) OverageCharge(ctx context.Context, l *dtos.Lease) (*dtos.LeasePayment, error) {
+    report, err := s.report(ctx, l)
+    if err != nil || report.Readings < 2 {
+        return nil, err
+    }
+    excess := report.MilesDriven - l.MileageLimit
+    amount := s.pricing.MileageOverageCharge(excess)
+    if amount <= 0 {
+        return nil, nil
+    }
+    return &dtos.LeasePayment{
+        DueDate:     dateOnly(time.Now()),
+        Amount:      amount,
+        Status:      dtos.LeasePaymentPending,
+        Kind:        dtos.LeasePaymentMileageOverage,
+        Description: fmt.Sprintf("%d miles over the %d mile limit", excess, l.MileageLimit),
+    }, nil
+}
+
+func (s *MileageService) activeLease(ctx context.Context, id string) (*dtos.Lease, error) {
+    l, err := s.leases.GetByID(ctx, id)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrLeaseNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    if l.Status != dtos.LeaseStatusActive {
+        return nil, &InvalidTransitionError{Action: "record mileage for", From: l.Status}
+    }
+    return l, nil
+}
+
+func (s *MileageService) report(ctx context.Context, l *dtos.Lease) (*dtos.MileageReport, error) {
+    readings, err := s.readings.ListByLease(ctx, l.ID)
+    if err != nil {
+        return nil, err
+    }
+    return buildMileageReport(l, readings), nil
+}
+
+func (s *MileageService) publishWarning(r *dtos.MileageReport) {
+    event := dtos.MileageWarningEvent{
+        Event:               "lease.mileage_warning",
+        LeaseID:             r.LeaseID,
+        MileageLimit:        r.MileageLimit,
+        ProjectedTotalMiles: r.ProjectedTotalMiles,
+    }
+    b, _ := json.Marshal(event)
+    if err := s.redisClient.Publish(context.Background(), "leases", string(b)); err != nil {
+        logger.Error("failed to publish mileage warning")
+    }
+}
+
+// buildMileageReport measures miles driven from the first reading (the
+// baseline taken from the vehicle at activation) and extrapolates the daily
+// rate since then to the end of the lease.
+func buildMileageReport(l *dtos.Lease, readings []dtos.OdometerReading) *dtos.MileageReport {
+    r := &dtos.MileageReport{LeaseID: l.ID, MileageLimit: l.MileageLimit, Readings: len(readings)}
+    termDays := daysBetween(dateOnly(l.StartDate), dateOnly(l.EndDate))
+    if termDays > 0 {
+        r.AnnualAllowance = math.Round(float64(l.MileageLimit) / float64(termDays) * 365)
+    }
+    if len(readings) == 0 {
+        return r
+    }
+
+    first, last := readings[0], readings[len(readings)-1]
+    r.BaselineReading, r.LatestReading = first.Reading, last.Reading
+    r.MilesDriven = last.Reading - first.Reading
+    r.ProjectedTotalMiles = float64(r.MilesDriven)
+
+    elapsed := last.RecordedAt.Sub(first.RecordedAt).Hours() / 24
+    if elapsed >= 1 {
+        perDay := float64(r.MilesDriven) / elapsed
+        remaining := math.Max(0, l.EndDate.Sub(last.RecordedAt).Hours()/24)
+        r.AnnualizedMiles = math.Round(perDay * 365)
+        r.ProjectedTotalMiles = math.Round(float64(r.MilesDriven) + perDay*remaining)
+    }
+    r.ProjectedOverage = math.Max(0, r.ProjectedTotalMiles-float64(l.MileageLimit))
+    r.OnTrackToExceed = r.ProjectedOverage > 0
+    return r
+}
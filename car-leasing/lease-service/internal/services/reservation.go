@@ -0,0 +1,236 @@
+package services
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "strconv"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+    "leaseCar/lease-service/internal/dtos"
+    "leaseCar/lease-service/internal/repositories"
+    "leaseCar/lease-service/internal/validation"
+    redisutil "leaseCar/utils/redis"
+)
+
+const (
+    DefaultHoldTTL = 15 * time.Minute
+    MaxHoldTTL     = time.Hour
+)
+
+var (
+    ErrHoldNotFound       = errors.New("vehicle has no active hold for this user")
+    ErrInvalidHold        = errors.New("user_id must be a UUID, ttl_seconds must not exceed 3600 and end_date must be after start_date")
+    ErrVehicleHeld        = errors.New("vehicle is held by another user")
+    ErrVehicleUnavailable = errors.New("vehicle needs repair and cannot be held")
+)
+
+// vehicleHoldsKey is a sorted set of held vehicle ids scored by hold expiry
+// (unix ms), so listings can find held vehicles without scanning keys.
+const vehicleHoldsKey = "vehicle:holds"
+
+// holdScript takes a hold, or extends it while it still belongs to the same
+// user, so an expired hold that another user has since taken is not
+// overwritten.
+const holdScript = `
+local cur = redis.call("GET", KEYS[1])
+if cur and cjson.decode(cur).user_id ~= ARGV[1] then
+    return 0
+end
+redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+redis.call("ZADD", KEYS[2], ARGV[4], ARGV[5])
+return 1`
+
+// releaseHoldScript deletes a hold only if it belongs to the given user.
+const releaseHoldScript = `
+local cur = redis.call("GET", KEYS[1])
+if not cur or cjson.decode(cur).user_id ~= ARGV[1] then
+    return 0
+end
+redis.call("ZREM", KEYS[2], ARGV[2])
+return redis.call("DEL", KEYS[1])`
+
+// ReservationService places time-limited holds on vehicles between quote
+// and signing. Holds live only in Redis under vehicle:hold:<id>; the key's
+// TTL is what releases them.
+type ReservationService struct {
+    vehicles    *repositories.VehicleRepository
+    leases      *repositories.LeaseRepository
+    redisClient *redisutil.Client
+}
+
+func NewReservationService(v *repositories.VehicleRepository, l *repositories.LeaseRepository, rc *redisutil.Client) *ReservationService {
+    return &ReservationService{vehicles: v, leases: l, redisClient: rc}
+}
+
+func vehicleHoldKey(vehicleID string) string {
+    return "vehicle:hold:" + vehicleID
+}
+
+// Hold reserves a vehicle for a user who intends to lease it for
+// [start_date, end_date), which defaults to a year from today as on lease
+// creation. The period must not overlap a lease that already holds the
+// vehicle, and vehicles waiting for repair cannot be held. Holding a vehicle
+// the user already holds restarts its expiry.
+func (s *ReservationService) Hold(ctx context.Context, vehicleID string, in *dtos.VehicleHoldRequest) (*dtos.VehicleHold, error) {
+    ttl := time.Duration(in.TTLSeconds) * time.Second
+    if in.TTLSeconds == 0 {
+        ttl = DefaultHoldTTL
+    }
+    start, end := in.StartDate, in.EndDate
+    if start.IsZero() {
+        start = time.Now()
+    }
+    if end.IsZero() {
+        end = start.AddDate(0, 12, 0)
+    }
+    if !validation.IsUUID(in.UserID) || ttl < 0 || ttl > MaxHoldTTL || !end.After(start) {
+        return nil, ErrInvalidHold
+    }
+    if !validation.IsUUID(vehicleID) {
+        return nil, ErrVehicleNotFound
+    }
+    vehicle, err := s.vehicles.GetByID(ctx, vehicleID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrVehicleNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    if vehicle.NeedsRepair {
+        return nil, ErrVehicleUnavailable
+    }
+    conflict, err := s.leases.FindOverlap(ctx, vehicleID, start, end)
+    if err != nil {
+        return nil, err
+    }
+    if conflict != "" {
+        return nil, &LeaseConflictError{LeaseID: conflict}
+    }
+
+    now := time.Now().UTC()
+    hold := &dtos.VehicleHold{VehicleID: vehicleID, UserID: in.UserID, CreatedAt: now, ExpiresAt: now.Add(ttl)}
+    b, err := json.Marshal(hold)
+    if err != nil {
+        return nil, err
+    }
+    keys := []string{vehicleHoldKey(vehicleID), vehicleHoldsKey}
+    taken, err := s.redisClient.Eval(ctx, holdScript, keys, in.UserID, string(b), ttl.Milliseconds(), hold.ExpiresAt.UnixMilli(), vehicleID)
+    if err != nil {
+        return nil, err
+    }
+    if n, _ := taken.(int64); n == 0 {
+        return nil, ErrVehicleHeld
+    }
+    return hold, nil
+}
+
+// Get returns the active hold on a vehicle, or nil if there is none.
+func (s *ReservationService) Get(ctx context.Context, vehicleID string) (*dtos.VehicleHold, error) {
+    raw, err := s.redisClient.Get(ctx, vehicleHoldKey(vehicleID))
+    if errors.Is(err, redisutil.Nil) {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    var hold dtos.VehicleHold
+    if err := json.Unmarshal([]byte(raw), &hold); err != nil {
+        return nil, err
+    }
+    return &hold, nil
+}
+
+// Release drops a user's hold on a vehicle.
+func (s *ReservationService) Release(ctx context.Context, vehicleID, userID string) error {
+    n, err := s.redisClient.Eval(ctx, releaseHoldScript, []string{vehicleHoldKey(vehicleID), vehicleHoldsKey}, userID, vehicleID)
+    if err != nil {
+        return err
+    }
+    if deleted, _ := n.(int64); deleted == 0 {
+        return ErrHoldNotFound
+    }
+    return nil
+}
+
+// CheckAvailable returns ErrVehicleHeld if another user holds the vehicle.
+func (s *ReservationService) CheckAvailable(ctx context.Context, vehicleID, userID string) error {
+    hold, err := s.Get(ctx, vehicleID)
+    if err != nil {
+        return err
+    }
+    if hold != nil && hold.UserID != userID {
+        return ErrVehicleHeld
+    }
+    return nil
+}
+
+// heldByOthers returns the ids of vehicles currently held by anyone other
+// than viewerID. Expired entries are pruned from the index first.
+func (s *ReservationService) heldByOthers(ctx context.Context, viewerID string) ([]string, error) {
+    now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+    if _, err := s.redisClient.ZRemRangeByScore(ctx, vehicleHoldsKey, "-inf", now); err != nil {
+        return nil, err
+    }
+    ids, err := s.redisClient.ZRangeByScore(ctx, vehicleHoldsKey, "("+now, "+inf")
+    if err != nil || len(ids) == 0 {
+        return nil, err
+    }
+    keys := make([]string, len(ids))
+    for i, id := range ids {
+        keys[i] = vehicleHoldKey(id)
+    }
+    values, err := s.redisClient.MGet(ctx, keys...)
+    if err != nil {
+        return nil, err
+    }
+    held := []string{}
+    for i, raw := range values {
+        str, ok := raw.(string)
+        if !ok {
+            continue
+        }
+        var hold dtos.VehicleHold
+        if err := json.Unmarshal([]byte(str), &hold); err != nil {
+            return nil, err
+        }
+        if hold.UserID != viewerID {
+            held = append(held, ids[i])
+        }
+    }
+    return held, nil
+}
+
+// applyHolds marks vehicles held by someone other than viewerID as
+// unavailable until their hold expires.
+func (s *ReservationService) applyHolds(ctx context.Context, vehicles []dtos.Vehicle, viewerID string) error {
+    if len(vehicles) == 0 {
+        return nil
+    }
+    keys := make([]string, len(vehicles))
+    for i, v := range vehicles {
+        keys[i] = vehicleHoldKey(v.ID)
+    }
+    values, err := s.redisClient.MGet(ctx, keys...)
+    if err != nil {
+        return err
+    }
+    for i, raw := range values {
+        str, ok := raw.(string)
+        if !ok {
+            continue
+        }
+        var hold dtos.VehicleHold
+        if err := json.Unmarshal([]byte(str), &hold); err != nil {
+            return err
+        }
+        if hold.UserID == viewerID {
+            continue
+        }
+        expires := hold.ExpiresAt
+        vehicles[i].Available = false
+        vehicles[i].HeldUntil = &expires
+    }
+    return nil
+}
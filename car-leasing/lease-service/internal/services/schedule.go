@@ -0,0 +1,52 @@
+package services
+
+import (
+    "math"
+    "time"
+
+    "leaseCar/lease-service/internal/dtos"
+)
+
+// BuildSchedule splits the lease period [start, end) into calendar-month
+// installments, each due on the first day it covers. Full months bill the
+// monthly amount; a partial first or last month is prorated by the number of
+// days it covers in that month.
+func BuildSchedule(start, end time.Time, monthly float64) []dtos.LeasePayment {
+    start, end = dateOnly(start), dateOnly(end)
+    var schedule []dtos.LeasePayment
+    for cur := start; cur.Before(end); {
+        monthStart := time.Date(cur.Year(), cur.Month(), 1, 0, 0, 0, 0, time.UTC)
+        nextMonth := monthStart.AddDate(0, 1, 0)
+        periodEnd := nextMonth
+        if end.Before(nextMonth) {
+            periodEnd = end
+        }
+
+        amount := monthly
+        covered, inMonth := daysBetween(cur, periodEnd), daysBetween(monthStart, nextMonth)
+        if covered < inMonth {
+            amount = roundCents(monthly * float64(covered) / float64(inMonth))
+        }
+
+        schedule = append(schedule, dtos.LeasePayment{
+            PaymentNumber: len(schedule) + 1,
+            DueDate:       cur,
+            Amount:        amount,
+            Status:        dtos.LeasePaymentPending,
+        })
+        cur = periodEnd
+    }
+    return schedule
+}
+
+func dateOnly(t time.Time) time.Time {
+    return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(from, to time.Time) int {
+    return int(to.Sub(from).Hours() / 24)
+}
+
+func roundCents(v float64) float64 {
+    return math.Round(v*100) / 100
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+    "testing"
+    "time"
+
+    "leaseCar/lease-service/internal/dtos"
+)
+
+func day(y int, m time.Month, d int) time.Time {
+    return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestBuildSchedule(t *testing.T) {
+    type item struct {
+        due    time.Time
+        amount float64
+    }
+    tests := []struct {
+        name       string
+        start, end time.Time
+        want       []item
+    }{
+        {name: "full months", start: day(2026, 1, 1), end: day(2026, 4, 1),
+            want: []item{{day(2026, 1, 1), 300}, {day(2026, 2, 1), 300}, {day(2026, 3, 1), 300}}},
+        // 17 of 31 January days, all of February, 14 of 31 March days
+        {name: "partial first and last month", start: day(2026, 1, 15), end: day(2026, 3, 15),
+            want: []item{{day(2026, 1, 15), 164.52}, {day(2026, 2, 1), 300}, {day(2026, 3, 1), 135.48}}},
+        {name: "leap february", start: day(2028, 2, 10), end: day(2028, 3, 1),
+            want: []item{{day(2028, 2, 10), 206.9}}},
+        {name: "within one month", start: day(2026, 6, 10), end: day(2026, 6, 20),
+            want: []item{{day(2026, 6, 10), 100}}},
+        {name: "empty period", start: day(2026, 1, 1), end: day(2026, 1, 1)},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got := BuildSchedule(tt.start, tt.end, 300)
+            if len(got) != len(tt.want) {
+                t.Fatalf("got %d installments, want %d: %+v", len(got), len(tt.want), got)
+            }
+            for i, p := range got {
+                if p.PaymentNumber != i+1 || !p.DueDate.Equal(tt.want[i].due) || p.Amount != tt.want[i].amount {
+                    t.Errorf("installment %d = #%d due %s for %.2f, want due %s for %.2f", i, p.PaymentNumber,
+                        p.DueDate.Format("2006-01-02"), p.Amount, tt.want[i].due.Format("2006-01-02"), tt.want[i].amount)
+                }
+                if p.Status != dtos.LeasePaymentPending {
+                    t.Errorf("installment %d status %s", i, p.Status)
+                }
+            }
+        })
+    }
+}
+
+func TestBuildScheduleIgnoresTimeOfDay(t *testing.T) {
+    start := time.Date(2026, 1, 1, 18, 30, 0, 0, time.UTC)
+    end := time.Date(2026, 3, 1, 6, 0, 0, 0, time.UTC)
+    got := BuildSchedule(start, end, 300)
+    if len(got) != 2 || got[0].Amount != 300 || got[1].Amount != 300 || !got[0].DueDate.Equal(day(2026, 1, 1)) {
+        t.Fatalf("got %+v, want two full months from 2026-01-01", got)
+    }
+}
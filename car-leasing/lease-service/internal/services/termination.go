@@ -0,0 +1,174 @@
+package services
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+    "leaseCar/lease-service/internal/dtos"
+    "leaseCar/lease-service/internal/pricing"
+    redisutil "leaseCar/utils/redis"
+)
+
+const terminationQuoteTTL = 24 * time.Hour
+
+var (
+    ErrQuoteRequired = errors.New("a termination quote_id is required to terminate an active lease")
+    ErrQuoteNotFound = errors.New("termination quote not found or expired")
+)
+
+// TerminationQuote prices ending an active lease today. The vehicle must
+// have been returned and inspected first, since the deposit left after the
+// damage settlement is what the quote can offset. The quote is kept for 24
+// hours and must be presented to Terminate.
+func (s *LeaseService) TerminationQuote(ctx context.Context, id string) (*dtos.TerminationQuote, error) {
+    l, err := s.repo.GetByID(ctx, id)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrLeaseNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    if l.Status != dtos.LeaseStatusActive {
+        return nil, &InvalidTransitionError{Action: ActionTerminate, From: l.Status}
+    }
+
+    ins, err := s.inspections.Get(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+    schedule, err := s.payments.ListByLease(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+    quote := buildTerminationQuote(l, ins, schedule, s.pricing, time.Now())
+    if quote.ID, err = newQuoteID(); err != nil {
+        return nil, err
+    }
+
+    b, _ := json.Marshal(quote)
+    if err := s.redisClient.Set(ctx, terminationQuoteKey(quote.ID), string(b), terminationQuoteTTL); err != nil {
+        return nil, err
+    }
+    return quote, nil
+}
+
+// Terminate ends a lease early. Active leases need a valid quote from
+// TerminationQuote, and damage beyond the deposit is billed from their
+// return inspection; approved leases that never started end without
+// either.
+func (s *LeaseService) Terminate(ctx context.Context, id string, in *dtos.TerminateRequest) (*dtos.Lease, error) {
+    current, err := s.repo.GetByID(ctx, id)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrLeaseNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    if _, err := nextStatus(current.Status, ActionTerminate); err != nil {
+        return nil, err
+    }
+
+    var quote *dtos.TerminationQuote
+    var charges []dtos.LeasePayment
+    if current.Status == dtos.LeaseStatusActive {
+        if in.QuoteID == "" {
+            return nil, ErrQuoteRequired
+        }
+        if quote, err = s.loadQuote(ctx, id, in.QuoteID); err != nil {
+            return nil, err
+        }
+        damage, err := s.inspections.DamageCharge(ctx, current)
+        if err != nil {
+            return nil, err
+        }
+        if damage != nil {
+            charges = append(charges, *damage)
+        }
+    }
+
+    updated, err := s.repo.Terminate(ctx, id, current.Status, quote, charges)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrLeaseStatusChanged
+    }
+    if err != nil {
+        return nil, err
+    }
+    if quote != nil {
+        _, _ = s.redisClient.Del(ctx, terminationQuoteKey(quote.ID))
+    }
+
+    s.indexer.Notify()
+    s.publishStatusChanged(current.Status, updated, in.Reason)
+    return updated, nil
+}
+
+func (s *LeaseService) loadQuote(ctx context.Context, leaseID, quoteID string) (*dtos.TerminationQuote, error) {
+    raw, err := s.redisClient.Get(ctx, terminationQuoteKey(quoteID))
+    if errors.Is(err, redisutil.Nil) {
+        return nil, ErrQuoteNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    var quote dtos.TerminationQuote
+    if err := json.Unmarshal([]byte(raw), &quote); err != nil {
+        return nil, err
+    }
+    if quote.LeaseID != leaseID {
+        return nil, ErrQuoteNotFound
+    }
+    return &quote, nil
+}
+
+// buildTerminationQuote splits the schedule at today: installments due later
+// are remaining (and drive the fee), installments already due but not fully
+// paid are unpaid. The return inspection has already applied the deposit
+// still held (not carried over to a renewal) to the damage, so only what it
+// left for refund offsets the rest, and damage beyond the deposit is owed
+// on top.
+func buildTerminationQuote(l *dtos.Lease, ins *dtos.LeaseInspection, schedule []dtos.LeasePayment, calc *pricing.Calculator, now time.Time) *dtos.TerminationQuote {
+    q := &dtos.TerminationQuote{
+        LeaseID:      l.ID,
+        Deposit:      ins.DepositRefund,
+        DamageAmount: ins.AmountDue,
+        QuotedAt:     now,
+        ExpiresAt:    now.Add(terminationQuoteTTL),
+    }
+    today := dateOnly(now)
+    for _, p := range schedule {
+        switch {
+        case p.Kind == dtos.LeasePaymentInstallment && p.Status == dtos.LeasePaymentPending && p.DueDate.After(today):
+            q.RemainingInstallments++
+            q.RemainingAmount += p.Amount
+        case p.Status == dtos.LeasePaymentPending || p.Status == dtos.LeasePaymentOverdue:
+            q.UnpaidInstallments++
+            q.UnpaidAmount += p.Amount - p.PaidAmount
+        }
+    }
+    q.RemainingAmount = roundCents(q.RemainingAmount)
+    q.UnpaidAmount = roundCents(q.UnpaidAmount)
+    q.EarlyTerminationFee = calc.EarlyTerminationFee(q.RemainingAmount)
+
+    owed := q.UnpaidAmount + q.EarlyTerminationFee + q.DamageAmount
+    q.DepositOffset = roundCents(min(q.Deposit, owed))
+    q.AmountDue = roundCents(owed - q.DepositOffset)
+    q.DepositRefund = roundCents(q.Deposit - q.DepositOffset)
+    return q
+}
+
+func terminationQuoteKey(id string) string {
+    return "lease:termination-quote:" + id
+}
+
+func newQuoteID() (string, error) {
+    b := make([]byte, 16)
+    if _, err := rand.Read(b); err != nil {
+        return "", err
+    }
+    return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+    "context"
+    "time"
+
+    "go.uber.org/zap"
+    "leaseCar/lease-service/internal/adapters"
+    "leaseCar/lease-service/internal/repositories"
+    "leaseCar/utils/logger"
+)
+
+const vehicleIndexBatchSize = 200
+
+// VehicleIndexer keeps the Meilisearch "vehicles" index in step with
+// Postgres. Vehicles are picked up whenever their indexed_at lags
+// updated_at, and deleted vehicles stay queued in vehicle_index_deletions
+// until their document is removed, so a failed push is simply retried on
+// the next pass.
+type VehicleIndexer struct {
+    repo     *repositories.VehicleRepository
+    meili    *adapters.MeiliAdapter
+    interval time.Duration
+    wake     chan struct{}
+}
+
+func NewVehicleIndexer(r *repositories.VehicleRepository, m *adapters.MeiliAdapter, interval time.Duration) *VehicleIndexer {
+    return &VehicleIndexer{repo: r, meili: m, interval: interval, wake: make(chan struct{}, 1)}
+}
+
+// Notify asks the indexer to sync as soon as possible instead of waiting for
+// the next tick. It never blocks.
+func (i *VehicleIndexer) Notify() {
+    select {
+    case i.wake <- struct{}{}:
+    default:
+    }
+}
+
+// Run syncs pending vehicles until ctx is cancelled.
+func (i *VehicleIndexer) Run(ctx context.Context) {
+    ticker := time.NewTicker(i.interval)
+    defer ticker.Stop()
+    for {
+        if err := i.SyncPending(ctx); err != nil {
+            logger.Warn("vehicle index sync failed", zap.Error(err))
+        }
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        case <-i.wake:
+        }
+    }
+}
+
+// SyncPending pushes every vehicle with a missing or stale document and
+// removes the documents of deleted vehicles.
+func (i *VehicleIndexer) SyncPending(ctx context.Context) error {
+    for {
+        vehicles, err := i.repo.ListUnindexed(ctx, vehicleIndexBatchSize)
+        if err != nil {
+            return err
+        }
+        if len(vehicles) == 0 {
+            break
+        }
+        // IndexVehicle waits for the Meili task, so vehicles whose task
+        // failed stay unindexed and are pushed again on the next pass
+        if err := i.meili.IndexVehicle(ctx, "vehicles", vehicles); err != nil {
+            return err
+        }
+        if err := i.repo.MarkIndexed(ctx, vehicles); err != nil {
+            return err
+        }
+        if len(vehicles) < vehicleIndexBatchSize {
+            break
+        }
+    }
+    for {
+        ids, err := i.repo.ListDeleted(ctx, vehicleIndexBatchSize)
+        if err != nil || len(ids) == 0 {
+            return err
+        }
+        if err := i.meili.DeleteDocuments(ctx, "vehicles", ids); err != nil {
+            return err
+        }
+        if err := i.repo.ClearDeleted(ctx, ids); err != nil {
+            return err
+        }
+        if len(ids) < vehicleIndexBatchSize {
+            return nil
+        }
+    }
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "regexp"
+    "strings"
+
+    "github.com/jackc/pgx/v5"
+    "leaseCar/lease-service/internal/dtos"
+    "leaseCar/lease-service/internal/repositories"
+)
+
+var (
+    ErrInvalidVIN         = errors.New("vin must be 17 characters of A-Z and 0-9, excluding I, O and Q")
+    ErrInvalidVehicleType = errors.New("vehicle_type must be one of " + strings.Join(dtos.VehicleTypes, ", "))
+    ErrInvalidVehicle     = errors.New("make, model, year and a positive price_per_month are required")
+)
+
+// vinPattern matches ISO 3779 VINs: 17 characters, no I, O or Q.
+var vinPattern = regexp.MustCompile(`^[A-HJ-NPR-Z0-9]{17}$`)
+
+type VehicleService struct {
+    repo    *repositories.VehicleRepository
+    indexer *VehicleIndexer
+    holds   *ReservationService
+}
+
+func NewVehicleService(r *repositories.VehicleRepository, idx *VehicleIndexer, h *ReservationService) *VehicleService {
+    return &VehicleService{repo: r, indexer: idx, holds: h}
+}
+
+func (s *VehicleService) Create(ctx context.Context, in *dtos.VehicleCreateRequest) (*dtos.Vehicle, error) {
+    in.VIN = strings.ToUpper(strings.TrimSpace(in.VIN))
+    if !vinPattern.MatchString(in.VIN) {
+        return nil, ErrInvalidVIN
+    }
+    if !validVehicleType(in.VehicleType) {
+        return nil, ErrInvalidVehicleType
+    }
+    if in.Make == "" || in.Model == "" || in.Year <= 0 || in.PricePerMonth <= 0 {
+        return nil, ErrInvalidVehicle
+    }
+
+    v, err := s.repo.Create(ctx, in)
+    if err != nil {
+        return nil, err
+    }
+    s.indexer.Notify()
+    return v, nil
+}
+
+// GetByID returns a vehicle as viewerID sees it: a hold by anyone else
+// makes it unavailable.
+func (s *VehicleService) GetByID(ctx context.Context, id, viewerID string) (*dtos.Vehicle, error) {
+    v, err := s.repo.GetByID(ctx, id)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrVehicleNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    vehicles := []dtos.Vehicle{*v}
+    if err := s.holds.applyHolds(ctx, vehicles, viewerID); err != nil {
+        return nil, err
+    }
+    return &vehicles[0], nil
+}
+
+func (s *VehicleService) List(ctx context.Context, f dtos.VehicleFilter) ([]dtos.Vehicle, error) {
+    if f.VehicleType != "" && !validVehicleType(f.VehicleType) {
+        return nil, ErrInvalidVehicleType
+    }
+    if f.Limit <= 0 || f.Limit > 100 {
+        f.Limit = 20
+    }
+    if f.Offset < 0 {
+        f.Offset = 0
+    }
+    // vehicles held by someone else count as unavailable in the query itself,
+    // so an available=true page is never cut short after paging
+    if f.Available != nil {
+        held, err := s.holds.heldByOthers(ctx, f.ViewerID)
+        if err != nil {
+            return nil, err
+        }
+        f.Held = held
+    }
+    vehicles, err := s.repo.List(ctx, f)
+    if err != nil {
+        return nil, err
+    }
+    if err := s.holds.applyHolds(ctx, vehicles, f.ViewerID); err != nil {
+        return nil, err
+    }
+    return vehicles, nil
+}
+
+func (s *VehicleService) Update(ctx context.Context, id string, in *dtos.VehicleUpdateRequest) (*dtos.Vehicle, error) {
+    if in.VehicleType != nil && !validVehicleType(*in.VehicleType) {
+        return nil, ErrInvalidVehicleType
+    }
+    if (in.Make != nil && *in.Make == "") || (in.Model != nil && *in.Model == "") ||
+        (in.Year != nil && *in.Year <= 0) || (in.PricePerMonth != nil && *in.PricePerMonth <= 0) {
+        return nil, ErrInvalidVehicle
+    }
+
+    v, err := s.repo.Update(ctx, id, in)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, ErrVehicleNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    s.indexer.Notify()
+    return v, nil
+}
+
+func (s *VehicleService) Delete(ctx context.Context, id string) error {
+    err := s.repo.Delete(ctx, id)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return ErrVehicleNotFound
+    }
+    if err != nil {
+        return err
+    }
+    s.indexer.Notify()
+    return nil
+}
+
+func validVehicleType(t string) bool {
+    for _, vt := range dtos.VehicleTypes {
+        if t == vt {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,42 @@
+package validation
+
+import (
+    "regexp"
+    "strings"
+)
+
+// FieldError describes a single invalid request field.
+type FieldError struct {
+    Field   string `json:"field"`
+    Message string `json:"message"`
+}
+
+// Errors collects field errors; it is returned as an error only when
+// non-empty.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+    msgs := make([]string, len(e))
+    for i, f := range e {
+        msgs[i] = f.Field + ": " + f.Message
+    }
+    return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+func (e *Errors) Add(field, message string) {
+    *e = append(*e, FieldError{Field: field, Message: message})
+}
+
+// Err returns e as an error, or nil when there are no field errors.
+func (e Errors) Err() error {
+    if len(e) == 0 {
+        return nil
+    }
+    return e
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func IsUUID(s string) bool {
+    return uuidPattern.MatchString(s)
+}
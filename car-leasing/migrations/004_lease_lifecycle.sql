@@ -0,0 +1,5 @@
+-- 004_lease_lifecycle.sql - Lease lifecycle state machine support
+
+-- APPROVED sits between PENDING_APPROVAL and ACTIVE: the lease has been
+-- accepted but has not started yet.
+ALTER TYPE lease_status ADD VALUE IF NOT EXISTS 'APPROVED' AFTER 'PENDING_APPROVAL';
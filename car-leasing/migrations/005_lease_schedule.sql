@@ -0,0 +1,4 @@
+-- 005_lease_schedule.sql - Installment schedule constraints
+
+-- One row per installment; lets schedule generation be retried safely.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_lease_payments_lease_number ON lease_payments(lease_id, payment_number);
@@ -0,0 +1,3 @@
+-- 006_lease_pricing.sql - Server-side lease pricing breakdown
+
+ALTER TABLE leases ADD COLUMN IF NOT EXISTS pricing_breakdown JSONB;
@@ -0,0 +1,14 @@
+-- 007_vehicle_index_sync.sql - Track Meilisearch sync state per vehicle
+
+-- A vehicle needs (re)indexing whenever indexed_at is missing or older than
+-- updated_at; every write to a vehicle bumps updated_at.
+ALTER TABLE vehicles ADD COLUMN IF NOT EXISTS indexed_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_vehicles_index_pending ON vehicles(updated_at)
+  WHERE indexed_at IS NULL OR indexed_at < updated_at;
+
+-- Deleted vehicles stay here until their document is gone from the index.
+CREATE TABLE IF NOT EXISTS vehicle_index_deletions (
+  vehicle_id UUID PRIMARY KEY,
+  deleted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,10 @@
+-- 008_lease_overlap.sql - Prevent double-booking a vehicle
+
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+-- A vehicle can only be held by one lease for any given day. Drafts do not
+-- hold the vehicle until they are submitted. Lease periods are half-open:
+-- [start_date, end_date).
+ALTER TABLE leases ADD CONSTRAINT leases_vehicle_no_overlap
+  EXCLUDE USING gist (vehicle_id WITH =, daterange(start_date, end_date) WITH &&)
+  WHERE (status IN ('PENDING_APPROVAL', 'APPROVED', 'ACTIVE'));
@@ -0,0 +1,8 @@
+-- 009_lease_index_sync.sql - Track Meilisearch sync state per lease
+
+-- A lease needs (re)indexing whenever indexed_at is missing or older than
+-- updated_at; every write to a lease bumps updated_at.
+ALTER TABLE leases ADD COLUMN IF NOT EXISTS indexed_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_leases_index_pending ON leases(updated_at, id)
+  WHERE indexed_at IS NULL OR indexed_at < updated_at;
@@ -0,0 +1,3 @@
+-- 010_leases_user_keyset.sql - Keyset pagination of a user's leases
+
+CREATE INDEX IF NOT EXISTS idx_leases_user_created ON leases(user_id, created_at DESC, id DESC);
@@ -0,0 +1,4 @@
+-- 011_lease_termination.sql - Early termination
+
+-- The termination quote accepted when the lease was ended early.
+ALTER TABLE leases ADD COLUMN IF NOT EXISTS termination_quote JSONB;
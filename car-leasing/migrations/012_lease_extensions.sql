@@ -0,0 +1,26 @@
+-- 012_lease_extensions.sql - Lease extension and renewal
+
+-- A renewal is a new lease that points back at the lease it continues.
+ALTER TABLE leases ADD COLUMN IF NOT EXISTS previous_lease_id UUID REFERENCES leases(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_leases_previous_lease_id ON leases(previous_lease_id);
+
+-- Part of deposit_paid that a renewal took over; only the rest is refunded
+-- when the lease ends. Reset when the renewal is rejected or cancelled.
+ALTER TABLE leases ADD COLUMN IF NOT EXISTS deposit_transferred DECIMAL(10, 2) NOT NULL DEFAULT 0;
+
+-- Leases withdrawn before approval, e.g. a renewal that is not wanted.
+ALTER TYPE lease_status ADD VALUE IF NOT EXISTS 'CANCELLED';
+
+-- Each extension of a lease's end date, with the terms before and after.
+CREATE TABLE IF NOT EXISTS lease_extensions (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  lease_id UUID NOT NULL REFERENCES leases(id) ON DELETE CASCADE,
+  previous_end_date DATE NOT NULL,
+  new_end_date DATE NOT NULL,
+  added_installments INTEGER NOT NULL,
+  added_amount DECIMAL(10, 2) NOT NULL,
+  reason TEXT,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_lease_extensions_lease_id ON lease_extensions(lease_id);
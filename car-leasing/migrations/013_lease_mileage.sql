@@ -0,0 +1,17 @@
+-- 013_lease_mileage.sql - Odometer readings and non-installment charges
+
+-- lease_payments holds every billable item for a lease. Regular installments
+-- are kind INSTALLMENT; one-off charges (e.g. mileage overage) use their own
+-- kind so payment-service can collect them by lease_payment_id as well.
+ALTER TABLE lease_payments ADD COLUMN IF NOT EXISTS kind VARCHAR(30) NOT NULL DEFAULT 'INSTALLMENT';
+ALTER TABLE lease_payments ADD COLUMN IF NOT EXISTS description TEXT;
+
+CREATE TABLE IF NOT EXISTS lease_odometer_readings (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  lease_id UUID NOT NULL REFERENCES leases(id) ON DELETE CASCADE,
+  reading INTEGER NOT NULL CHECK (reading >= 0),
+  recorded_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_lease_odometer_readings_lease_id ON lease_odometer_readings(lease_id, recorded_at);
@@ -0,0 +1,5 @@
+-- 014_lease_scheduler.sql - Indexes for the date-driven scheduler passes
+
+CREATE INDEX IF NOT EXISTS idx_leases_approved_start ON leases(start_date) WHERE status = 'APPROVED';
+CREATE INDEX IF NOT EXISTS idx_leases_active_end ON leases(end_date) WHERE status = 'ACTIVE';
+CREATE INDEX IF NOT EXISTS idx_lease_payments_pending_due ON lease_payments(due_date) WHERE status = 'PENDING';
@@ -0,0 +1,9 @@
+-- 015_late_fees.sql - Late fees as billable items linked to the overdue item
+
+-- A LATE_FEE item points at the installment (or other charge) it penalizes.
+-- Each overdue item carries at most one fee row, which accrues in place.
+ALTER TABLE lease_payments ADD COLUMN IF NOT EXISTS related_payment_id UUID REFERENCES lease_payments(id) ON DELETE CASCADE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_lease_payments_late_fee
+  ON lease_payments(related_payment_id) WHERE kind = 'LATE_FEE';
+CREATE INDEX IF NOT EXISTS idx_lease_payments_overdue ON lease_payments(due_date) WHERE status = 'OVERDUE';
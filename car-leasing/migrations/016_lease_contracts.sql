@@ -0,0 +1,16 @@
+-- 016_lease_contracts.sql - Fingerprints of generated contract PDFs
+
+-- One row per distinct document generated for a lease. The PDF itself is
+-- rendered deterministically from the lease, so the hash identifies exactly
+-- which terms a customer was shown and signed.
+CREATE TABLE IF NOT EXISTS lease_contracts (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  lease_id UUID NOT NULL REFERENCES leases(id) ON DELETE CASCADE,
+  sha256 CHAR(64) NOT NULL,
+  size_bytes INTEGER NOT NULL,
+  lease_updated_at TIMESTAMP NOT NULL,
+  generated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (lease_id, sha256)
+);
+
+CREATE INDEX idx_lease_contracts_lease_id ON lease_contracts(lease_id, generated_at);
@@ -0,0 +1,17 @@
+-- 017_lease_amendments.sql - Versioned mid-lease term changes
+
+-- Each amendment is a numbered version of the lease's terms. changes holds
+-- {"field": {"before": ..., "after": ...}} for every field it touched.
+CREATE TABLE IF NOT EXISTS lease_amendments (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  lease_id UUID NOT NULL REFERENCES leases(id) ON DELETE CASCADE,
+  version INTEGER NOT NULL,
+  actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  reason TEXT NOT NULL,
+  effective_date DATE NOT NULL,
+  changes JSONB NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (lease_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity_type, entity_id, created_at);
@@ -0,0 +1,14 @@
+-- 018_credit_decisions.sql - Credit decisions taken when leases are submitted
+
+CREATE TABLE IF NOT EXISTS lease_credit_decisions (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  lease_id UUID NOT NULL REFERENCES leases(id) ON DELETE CASCADE,
+  outcome VARCHAR(20) NOT NULL,            -- APPROVE, REJECT or REVIEW
+  decisioner VARCHAR(50) NOT NULL,
+  score INTEGER,
+  reasons JSONB NOT NULL,                  -- [{"rule", "outcome", "message"}]
+  inputs JSONB NOT NULL,                   -- figures the decision was based on
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_lease_credit_decisions_lease_id ON lease_credit_decisions(lease_id, created_at);
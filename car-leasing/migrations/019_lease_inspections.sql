@@ -0,0 +1,35 @@
+-- 019_lease_inspections.sql - Return inspections and damage assessment
+
+-- Vehicles returned with severe damage stay unavailable until cleared.
+ALTER TABLE vehicles ADD COLUMN IF NOT EXISTS needs_repair BOOLEAN NOT NULL DEFAULT false;
+
+-- One return inspection per lease. damage_total is settled against the
+-- lease's deposit: deposit_applied covers what it can, amount_due is billed
+-- as a DAMAGE item on completion and deposit_refund goes back to the lessee.
+CREATE TABLE IF NOT EXISTS lease_inspections (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  lease_id UUID NOT NULL UNIQUE REFERENCES leases(id) ON DELETE CASCADE,
+  inspector_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  odometer INTEGER NOT NULL CHECK (odometer >= 0),
+  checklist JSONB NOT NULL,
+  photos JSONB NOT NULL DEFAULT '[]',
+  notes TEXT,
+  damage_total DECIMAL(10, 2) NOT NULL,
+  deposit_applied DECIMAL(10, 2) NOT NULL,
+  amount_due DECIMAL(10, 2) NOT NULL,
+  deposit_refund DECIMAL(10, 2) NOT NULL,
+  requires_repair BOOLEAN NOT NULL,
+  inspected_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS lease_inspection_damages (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  inspection_id UUID NOT NULL REFERENCES lease_inspections(id) ON DELETE CASCADE,
+  area VARCHAR(50) NOT NULL,
+  description TEXT NOT NULL,
+  severity VARCHAR(20) NOT NULL CHECK (severity IN ('MINOR', 'MODERATE', 'SEVERE')),
+  repair_cost DECIMAL(10, 2) NOT NULL CHECK (repair_cost >= 0),
+  photos JSONB NOT NULL DEFAULT '[]'
+);
+
+CREATE INDEX idx_lease_inspection_damages_inspection_id ON lease_inspection_damages(inspection_id);
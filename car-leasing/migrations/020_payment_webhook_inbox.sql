@@ -0,0 +1,11 @@
+-- 020_payment_webhook_inbox.sql - Verified provider webhooks kept as an idempotent inbox
+
+-- events are stored before their payment is resolved
+ALTER TABLE payment_webhooks ALTER COLUMN payment_id DROP NOT NULL;
+ALTER TABLE payment_webhooks ADD COLUMN IF NOT EXISTS provider_event_id VARCHAR(255);
+ALTER TABLE payment_webhooks ADD COLUMN IF NOT EXISTS error TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payment_webhooks_provider_event
+  ON payment_webhooks(provider, provider_event_id);
+CREATE INDEX IF NOT EXISTS idx_payments_provider_transaction
+  ON payments(provider, transaction_id);
@@ -0,0 +1,27 @@
+-- 021_payment_idempotency.sql - Idempotency-Key handling for POST /payments
+
+-- Keys are scoped per user. A key is committed IN_PROGRESS before the
+-- provider is charged and moved to COMPLETED with the stored response, or
+-- FAILED when the charge may have gone through. Keys whose request never
+-- reached the provider are deleted so the key can be retried. Rows are
+-- removed once expires_at has passed.
+CREATE TABLE IF NOT EXISTS payment_idempotency_keys (
+  user_id UUID NOT NULL,
+  key VARCHAR(255) NOT NULL,
+  request_hash CHAR(64) NOT NULL,          -- SHA-256 of the normalized request
+  status VARCHAR(20) NOT NULL DEFAULT 'IN_PROGRESS',
+  payment_id UUID REFERENCES payments(id) ON DELETE SET NULL,
+  response_status INTEGER NOT NULL DEFAULT 0,
+  response_body JSONB,
+  error TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  expires_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX idx_payment_idempotency_keys_expires_at ON payment_idempotency_keys(expires_at);
+
+-- the payment a key created, so a key left IN_PROGRESS by a crash can be
+-- resolved from what was actually recorded
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(255);
+CREATE INDEX IF NOT EXISTS idx_payments_idempotency_key ON payments(user_id, idempotency_key) WHERE idempotency_key IS NOT NULL;
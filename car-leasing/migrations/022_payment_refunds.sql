@@ -0,0 +1,26 @@
+-- 022_payment_refunds.sql - Full and partial refunds of captured payments
+
+-- sum of refunds that are pending or succeeded; never exceeds amount
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS refunded_amount DECIMAL(10, 2) NOT NULL DEFAULT 0;
+ALTER TABLE payments ADD CONSTRAINT payments_refunded_amount_check CHECK (refunded_amount >= 0 AND refunded_amount <= amount);
+
+CREATE TABLE IF NOT EXISTS payment_refunds (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  payment_id UUID NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
+  amount DECIMAL(10, 2) NOT NULL CHECK (amount > 0),
+  currency VARCHAR(3) NOT NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'PENDING',   -- PENDING, SUCCEEDED or FAILED
+  reason TEXT,
+  provider_refund_id VARCHAR(255),
+  error_message TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  attempted_at TIMESTAMP,                   -- last time the provider answered PENDING
+  completed_at TIMESTAMP
+);
+
+CREATE INDEX idx_payment_refunds_payment_id ON payment_refunds(payment_id, created_at);
+
+-- the part of a payment credited to its lease_payments item, which refunds
+-- take back; payments completed before this migration are assumed credited
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS settled_amount DECIMAL(10, 2) NOT NULL DEFAULT 0;
+UPDATE payments SET settled_amount = amount WHERE status = 'COMPLETED' AND lease_payment_id IS NOT NULL;
@@ -0,0 +1,16 @@
+-- 023_payment_cancellation.sql - Cancelling PENDING and PROCESSING payments
+
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS cancellation_reason TEXT;
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP;
+
+-- A payment the provider charged after it was cancelled has its charge
+-- reversed: REFUND for a captured charge, VOID otherwise. reversal_status is
+-- PENDING until the provider confirms (REVERSED), and FAILED once retries
+-- are exhausted and someone has to act on it; error_message keeps the last
+-- outcome.
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS reversal_status VARCHAR(20);
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS reversal_method VARCHAR(10);
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS reversal_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS reversal_attempted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_payments_reversal_status ON payments(reversal_status) WHERE reversal_status IS NOT NULL;
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"time"
+
+	"leaseCar/payment-service/internal/controllers"
+	"leaseCar/payment-service/internal/factory"
+	"leaseCar/payment-service/internal/repositories"
+	"leaseCar/payment-service/internal/services"
+	redisutil "leaseCar/utils/redis"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Factory functions for dependency injection
+func NewPaymentRepository(pool *pgxpool.Pool) *repositories.PaymentRepository {
+	return repositories.NewPaymentRepository(pool)
+}
+
+func NewWebhookRepository(pool *pgxpool.Pool) *repositories.WebhookRepository {
+	return repositories.NewWebhookRepository(pool)
+}
+
+func NewIdempotencyRepository(pool *pgxpool.Pool) *repositories.IdempotencyRepository {
+	return repositories.NewIdempotencyRepository(pool)
+}
+
+func NewRefundRepository(pool *pgxpool.Pool) *repositories.RefundRepository {
+	return repositories.NewRefundRepository(pool)
+}
+
+func NewPaymentFactory(conf factory.Config) *factory.PaymentFactory {
+	return factory.NewPaymentFactory(conf)
+}
+
+func NewPaymentService(repo *repositories.PaymentRepository, webhooks *repositories.WebhookRepository, idempotency *repositories.IdempotencyRepository, refunds *repositories.RefundRepository, f *factory.PaymentFactory, r *redisutil.Client) *services.PaymentService {
+	return services.NewPaymentService(repo, webhooks, idempotency, refunds, f, r)
+}
+
+func NewMaintenance(svc *services.PaymentService, interval time.Duration) *services.Maintenance {
+	return services.NewMaintenance(svc, interval)
+}
+
+func NewPaymentController(svc *services.PaymentService) *controllers.PaymentController {
+	return controllers.NewPaymentController(svc)
+}
+
+func NewWebhookController(svc *services.PaymentService) *controllers.WebhookController {
+	return controllers.NewWebhookController(svc)
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	cfg "leaseCar/utils/config"
+	"leaseCar/payment-service/internal/factory"
 	"leaseCar/utils/logger"
 	redisutil "leaseCar/utils/redis"
 
@@ -25,6 +26,11 @@ func main() {
 		log.Fatalf("failed to load config: %v", err)
 	}
 
+	var paymentConf factory.Config
+	if err := cfg.LoadKey(configPath, "payment", &paymentConf); err != nil {
+		log.Fatalf("failed to load payment config: %v", err)
+	}
+
 	logger.Info("payment-service config loaded")
 
 	// DB
@@ -55,12 +61,24 @@ func main() {
 
 	// Wire components
 	repo := NewPaymentRepository(pool)
-	factory := NewPaymentFactory(conf)
-	svc := NewPaymentService(repo, factory, r)
+	providers := NewPaymentFactory(paymentConf)
+	svc := NewPaymentService(repo, NewWebhookRepository(pool), NewIdempotencyRepository(pool), NewRefundRepository(pool), providers, r)
 	paymentController := NewPaymentController(svc)
 	webhookController := NewWebhookController(svc)
 
+	// housekeeping
+	go NewMaintenance(svc, time.Minute).Run(context.Background())
+
 	app.Post("/payments", paymentController.Create)
+	// before /payments/:id, which would otherwise match it
+	app.Get("/payments/reversals", paymentController.ListReversals)
+	app.Get("/payments/:id", paymentController.Get)
+	app.Post("/payments/:id/cancel", paymentController.Cancel)
+	app.Post("/payments/:id/reversal", paymentController.RetryReversal)
+	app.Post("/payments/:id/refunds", paymentController.Refund)
+	app.Get("/payments/:id/refunds", paymentController.ListRefunds)
+	app.Get("/leases/:id/payments", paymentController.ListByLease)
+	app.Get("/users/:id/payments", paymentController.ListByUser)
 	app.Post("/webhooks/:provider", webhookController.Handle)
 
 	port := conf.Server.Port
@@ -1,7 +1,6 @@
 package adapters
 
 import (
-	"context"
 	"errors"
 	"time"
 
@@ -25,6 +24,26 @@ func (b *BankAdapter) SendPayment(req *dtos.PaymentRequest) (*BankResponse, erro
 	return &BankResponse{TransactionID: "bank_tx_" + time.Now().Format("20060102150405"), Status: "OK"}, nil
 }
 
+func (b *BankAdapter) SendRefund(req *dtos.ProviderRefundRequest) (*BankResponse, error) {
+	// In real implementation return the transfer through the bank API, keyed
+	// by req.RefundID so a resubmitted refund is not paid twice
+	if b.url == "" {
+		return nil, errors.New("bank adapter not configured")
+	}
+	if req.ProviderTxID == "" {
+		return nil, errors.New("payment has no bank transfer to refund")
+	}
+	return &BankResponse{TransactionID: "bank_rf_" + time.Now().Format("20060102150405"), Status: "OK"}, nil
+}
+
+func (b *BankAdapter) CancelTransfer(req *dtos.ProviderVoidRequest) error {
+	// In real implementation ask the bank to recall the unsettled transfer
+	if b.url == "" {
+		return errors.New("bank adapter not configured")
+	}
+	return nil
+}
+
 type BankResponse struct {
 	TransactionID string
 	Status string
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -22,9 +23,157 @@ func (pc *PaymentController) Create(c *fiber.Ctx) error {
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
-	resp, err := pc.svc.CreatePayment(ctx, &req)
-	if err != nil {
+	resp, replayed, err := pc.svc.CreatePaymentIdempotent(ctx, c.Get("Idempotency-Key"), &req)
+	switch {
+	case errors.Is(err, services.ErrInvalidIdempotencyKey), errors.Is(err, services.ErrIdempotencyKeyUser):
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrIdempotencyKeyReused):
+		return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrIdempotencyKeyInProgress), errors.Is(err, services.ErrIdempotencyKeyFailed):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrPaymentCancelled):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	case err != nil:
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
+	if replayed {
+		c.Set("Idempotent-Replayed", "true")
+	}
 	return c.Status(201).JSON(resp)
 }
+
+func (pc *PaymentController) Get(c *fiber.Ctx) error {
+	p, err := pc.svc.GetPayment(context.Background(), c.Params("id"))
+	if errors.Is(err, services.ErrPaymentNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": "not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "internal error"})
+	}
+	return c.JSON(p)
+}
+
+func (pc *PaymentController) ListByLease(c *fiber.Ctx) error {
+	payments, err := pc.svc.ListLeasePayments(context.Background(), c.Params("id"))
+	if err != nil {
+		return listError(c, err)
+	}
+	return c.JSON(fiber.Map{"payments": payments})
+}
+
+func (pc *PaymentController) ListByUser(c *fiber.Ctx) error {
+	in := dtos.PaymentListRequest{
+		UserID: c.Params("id"),
+		Status: c.Query("status"),
+		From:   c.Query("from"),
+		To:     c.Query("to"),
+		Cursor: c.Query("cursor"),
+		Limit:  c.QueryInt("limit", 20),
+	}
+	page, err := pc.svc.ListUserPayments(context.Background(), &in)
+	if err != nil {
+		return listError(c, err)
+	}
+	return c.JSON(page)
+}
+
+func (pc *PaymentController) Refund(c *fiber.Ctx) error {
+	var in dtos.RefundRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&in); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	res, err := pc.svc.RefundPayment(ctx, c.Params("id"), &in)
+	var notRefundable *services.PaymentNotRefundableError
+	var providerErr *services.ProviderError
+	switch {
+	case errors.Is(err, services.ErrPaymentNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": "not found"})
+	case errors.Is(err, services.ErrInvalidRefundAmount), errors.Is(err, services.ErrRefundExceedsCaptured):
+		return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+	case errors.As(err, &notRefundable):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	case errors.As(err, &providerErr):
+		return c.Status(502).JSON(fiber.Map{"error": err.Error()})
+	case err != nil:
+		return c.Status(500).JSON(fiber.Map{"error": "internal error"})
+	}
+	if res.Refund.Status == dtos.RefundPending {
+		return c.Status(202).JSON(res)
+	}
+	return c.Status(201).JSON(res)
+}
+
+func (pc *PaymentController) Cancel(c *fiber.Ctx) error {
+	var in dtos.CancelRequest
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	p, err := pc.svc.CancelPayment(ctx, c.Params("id"), &in)
+	var notCancellable *services.PaymentNotCancellableError
+	var providerErr *services.ProviderError
+	switch {
+	case errors.Is(err, services.ErrPaymentNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": "not found"})
+	case errors.Is(err, services.ErrInvalidCancelReason):
+		return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+	case errors.As(err, &notCancellable):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	case errors.As(err, &providerErr):
+		return c.Status(502).JSON(fiber.Map{"error": err.Error()})
+	case err != nil:
+		return c.Status(500).JSON(fiber.Map{"error": "internal error"})
+	}
+	return c.JSON(p)
+}
+
+// ListReversals lists payments charged after they were cancelled, by
+// reversal status (FAILED by default).
+func (pc *PaymentController) ListReversals(c *fiber.Ctx) error {
+	payments, err := pc.svc.ListReversals(context.Background(), c.Query("status"))
+	if err != nil {
+		return listError(c, err)
+	}
+	return c.JSON(payments)
+}
+
+func (pc *PaymentController) RetryReversal(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	p, err := pc.svc.RetryReversal(ctx, c.Params("id"))
+	switch {
+	case errors.Is(err, services.ErrPaymentNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": "not found"})
+	case errors.Is(err, services.ErrNoFailedReversal):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	case err != nil:
+		return c.Status(500).JSON(fiber.Map{"error": "internal error"})
+	}
+	return c.JSON(p)
+}
+
+func (pc *PaymentController) ListRefunds(c *fiber.Ctx) error {
+	refunds, err := pc.svc.ListRefunds(context.Background(), c.Params("id"))
+	if errors.Is(err, services.ErrPaymentNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": "not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "internal error"})
+	}
+	return c.JSON(fiber.Map{"refunds": refunds})
+}
+
+func listError(c *fiber.Ctx, err error) error {
+	var filterErr *services.InvalidFilterError
+	switch {
+	case errors.As(err, &filterErr), errors.Is(err, services.ErrInvalidCursor):
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	default:
+		return c.Status(500).JSON(fiber.Map{"error": "internal error"})
+	}
+}
@@ -1,8 +1,13 @@
 package controllers
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"leaseCar/payment-service/internal/services"
+	"leaseCar/payment-service/internal/webhooks"
 )
 
 type WebhookController struct{
@@ -12,12 +17,22 @@ type WebhookController struct{
 func NewWebhookController(s *services.PaymentService) *WebhookController { return &WebhookController{svc: s} }
 
 func (w *WebhookController) Handle(c *fiber.Ctx) error {
-	provider := c.Params("provider")
-	var payload map[string]interface{}
-	if err := c.BodyParser(&payload); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid payload"})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	header := func(key string) string { return c.Get(key) }
+	res, err := w.svc.HandleProviderWebhook(ctx, c.Params("provider"), header, c.Body())
+	switch {
+	case errors.Is(err, services.ErrUnknownProvider):
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, webhooks.ErrSignature):
+		return c.Status(401).JSON(fiber.Map{"error": "invalid signature"})
+	case errors.Is(err, webhooks.ErrMalformed):
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrWebhookPaymentNotFound):
+		// non-2xx so the provider redelivers once the payment is recorded
+		return c.Status(503).JSON(fiber.Map{"error": err.Error()})
+	case err != nil:
+		return c.Status(500).JSON(fiber.Map{"error": "internal error"})
 	}
-	// delegate to service
-	_ = w.svc.HandleProviderWebhook(c.Context(), provider, payload)
-	return c.Status(200).JSON(fiber.Map{"status": "ok"})
+	return c.Status(200).JSON(res)
 }
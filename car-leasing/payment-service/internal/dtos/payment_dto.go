@@ -10,6 +10,9 @@ type PaymentRequest struct {
 	Currency      string  `json:"currency"`
 	Method        string  `json:"method"`
 	Provider      string  `json:"provider"`
+	// IdempotencyKey is taken from the Idempotency-Key header and stored on
+	// the payment; it is not part of the request body or its hash.
+	IdempotencyKey string `json:"-"`
 }
 
 type PaymentResponse struct {
@@ -19,4 +22,75 @@ type PaymentResponse struct {
 	CreatedAt     time.Time `json:"created_at"`
 }
 
-*** End Patch
\ No newline at end of file
+// Payment is a stored payment as returned by the read endpoints.
+type Payment struct {
+	ID                 string     `json:"id"`
+	LeaseID            string     `json:"lease_id"`
+	LeasePaymentID     *string    `json:"lease_payment_id,omitempty"`
+	UserID             string     `json:"user_id"`
+	Amount             float64    `json:"amount"`
+	RefundedAmount     float64    `json:"refunded_amount"`
+	Currency           string     `json:"currency"`
+	Status             string     `json:"status"`
+	Method             string     `json:"method"`
+	Provider           string     `json:"provider"`
+	TransactionID      *string    `json:"transaction_id"`
+	BlockchainTxHash   *string    `json:"blockchain_tx_hash"`
+	ErrorMessage       *string    `json:"error_message"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+	CompletedAt        *time.Time `json:"completed_at"`
+	CancellationReason *string    `json:"cancellation_reason,omitempty"`
+	CancelledAt        *time.Time `json:"cancelled_at,omitempty"`
+	ReversalStatus     *string    `json:"reversal_status,omitempty"`
+	ReversalMethod     *string    `json:"reversal_method,omitempty"`
+	ReversalAttempts   int        `json:"reversal_attempts,omitempty"`
+}
+
+// Reversal of a charge the provider made after its payment was cancelled.
+const (
+	ReversalPending  = "PENDING"
+	ReversalReversed = "REVERSED"
+	ReversalFailed   = "FAILED"
+
+	ReversalRefund = "REFUND"
+	ReversalVoid   = "VOID"
+)
+
+type CancelRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ProviderVoidRequest is what a strategy needs to void an uncaptured charge
+// or an unsettled transfer.
+type ProviderVoidRequest struct {
+	PaymentID    string
+	ProviderTxID string
+	Reason       string
+}
+
+// PaymentCancelledEvent is published on the payments channel when a payment
+// is cancelled.
+type PaymentCancelledEvent struct {
+	Event          string  `json:"event"`
+	PaymentID      string  `json:"payment_id"`
+	LeaseID        string  `json:"lease_id"`
+	LeasePaymentID *string `json:"lease_payment_id,omitempty"`
+	Amount         float64 `json:"amount"`
+	Reason         string  `json:"reason"`
+	Status         string  `json:"status"`
+}
+
+type PaymentListRequest struct {
+	UserID string
+	Status string
+	From   string
+	To     string
+	Cursor string
+	Limit  int
+}
+
+type PaymentPage struct {
+	Payments   []Payment `json:"payments"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,60 @@
+package dtos
+
+import "time"
+
+const (
+	RefundPending   = "PENDING"
+	RefundSucceeded = "SUCCEEDED"
+	RefundFailed    = "FAILED"
+)
+
+// RefundRequest is the body of POST /payments/:id/refunds. A zero amount
+// refunds whatever is left of the payment.
+type RefundRequest struct {
+	Amount float64 `json:"amount"`
+	Reason string  `json:"reason"`
+}
+
+// ProviderRefundRequest is what a strategy needs to refund a charge.
+type ProviderRefundRequest struct {
+	PaymentID    string
+	RefundID     string
+	ProviderTxID string
+	Amount       float64
+	Currency     string
+	Reason       string
+}
+
+type ProviderRefundResponse struct {
+	ProviderRefundID string
+	Status           string
+}
+
+type Refund struct {
+	ID               string     `json:"id"`
+	PaymentID        string     `json:"payment_id"`
+	Amount           float64    `json:"amount"`
+	Currency         string     `json:"currency"`
+	Status           string     `json:"status"`
+	Reason           string     `json:"reason,omitempty"`
+	ProviderRefundID *string    `json:"provider_refund_id"`
+	ErrorMessage     *string    `json:"error_message"`
+	CreatedAt        time.Time  `json:"created_at"`
+	CompletedAt      *time.Time `json:"completed_at"`
+}
+
+type RefundResult struct {
+	Refund  *Refund  `json:"refund"`
+	Payment *Payment `json:"payment"`
+}
+
+// PaymentRefundedEvent is published on the payments channel once a refund
+// succeeds.
+type PaymentRefundedEvent struct {
+	Event          string  `json:"event"`
+	PaymentID      string  `json:"payment_id"`
+	RefundID       string  `json:"refund_id"`
+	Amount         float64 `json:"amount"`
+	RefundedAmount float64 `json:"refunded_amount"`
+	Status         string  `json:"status"`
+}
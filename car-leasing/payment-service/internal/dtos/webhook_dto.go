@@ -0,0 +1,35 @@
+package dtos
+
+import "encoding/json"
+
+const (
+	PaymentStatusPending    = "PENDING"
+	PaymentStatusProcessing = "PROCESSING"
+	PaymentStatusCompleted  = "COMPLETED"
+	PaymentStatusFailed     = "FAILED"
+	PaymentStatusRefunded   = "REFUNDED"
+	PaymentStatusCancelled  = "CANCELLED"
+)
+
+// WebhookEvent is a verified provider event normalized for processing.
+// Status is the payment status the event reports, or "" for events that
+// don't move the payment.
+type WebhookEvent struct {
+	ID            string          `json:"id,omitempty"`
+	Provider      string          `json:"provider"`
+	EventID       string          `json:"event_id"`
+	EventType     string          `json:"event_type"`
+	TransactionID string          `json:"transaction_id,omitempty"`
+	PaymentID     string          `json:"payment_id,omitempty"`
+	Status        string          `json:"status,omitempty"`
+	Data          json.RawMessage `json:"-"`
+}
+
+type WebhookResult struct {
+	WebhookID string `json:"webhook_id"`
+	EventID   string `json:"event_id"`
+	PaymentID string `json:"payment_id,omitempty"`
+	Status    string `json:"status,omitempty"`
+	Applied   bool   `json:"applied"`
+	Duplicate bool   `json:"duplicate"`
+}
@@ -1,34 +1,54 @@
 package factory
 
 import (
+	"time"
+
 	"leaseCar/payment-service/internal/adapters"
 	"leaseCar/payment-service/internal/strategies"
-	cfg "leaseCar/utils/config"
+	"leaseCar/payment-service/internal/webhooks"
 )
 
+// ProviderConfig is one entry of payment.providers in config.yaml.
+type ProviderConfig struct {
+	APIKey                  string `mapstructure:"api_key"`
+	URL                     string `mapstructure:"url"`
+	WebhookSecret           string `mapstructure:"webhook_secret"`
+	WebhookToleranceSeconds int    `mapstructure:"webhook_tolerance_seconds"`
+}
+
+// Config is the payment section of config.yaml.
+type Config struct {
+	Providers map[string]ProviderConfig `mapstructure:"providers"`
+}
+
 type PaymentFactory struct {
-	conf *cfg.Config
+	conf Config
 }
 
-func NewPaymentFactory(conf *cfg.Config) *PaymentFactory { return &PaymentFactory{conf: conf} }
+func NewPaymentFactory(conf Config) *PaymentFactory { return &PaymentFactory{conf: conf} }
 
 func (f *PaymentFactory) GetStrategy(provider string) strategies.PaymentStrategy {
+	p := f.conf.Providers[provider]
 	switch provider {
 	case "stripe":
-		apiKey := ""
-		if f.conf != nil {
-			// try to read if available
-		}
-		return strategies.NewStripeStrategy(apiKey)
+		return strategies.NewStripeStrategy(p.APIKey)
 	case "bank_api":
-		url := ""
-		apiKey := ""
-		if f.conf != nil {
-			url = f.conf.MeiliSearch.URL // placeholder - config must be extended per provider
-		}
-		adapter := adapters.NewBankAdapter(url, apiKey)
+		adapter := adapters.NewBankAdapter(p.URL, p.APIKey)
 		return strategies.NewBankStrategy(adapter)
 	default:
 		return nil
 	}
 }
+
+// GetWebhookProvider returns the verifier/parser for a provider's webhooks.
+func (f *PaymentFactory) GetWebhookProvider(provider string) webhooks.Provider {
+	p := f.conf.Providers[provider]
+	switch provider {
+	case "stripe":
+		return webhooks.NewStripeProvider(p.WebhookSecret, time.Duration(p.WebhookToleranceSeconds)*time.Second)
+	case "bank_api":
+		return webhooks.NewBankProvider(p.WebhookSecret)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,251 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"leaseCar/payment-service/internal/dtos"
+)
+
+var (
+	// ErrIdempotencyKeyReused means the key was first used with another request.
+	ErrIdempotencyKeyReused = errors.New("idempotency key was used with a different request")
+	// ErrIdempotencyKeyInProgress means an earlier request with the key hasn't finished.
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still in progress")
+	// ErrIdempotencyKeyFailed means an earlier request with the key failed; it may
+	// have charged the provider, so it is not retried under the same key.
+	ErrIdempotencyKeyFailed = errors.New("a request with this idempotency key failed; retry with a new key")
+)
+
+const (
+	idempotencyInProgress = "IN_PROGRESS"
+	idempotencyCompleted  = "COMPLETED"
+	idempotencyFailed     = "FAILED"
+)
+
+const (
+	// IdempotencyKeyTTL is how long a key is remembered; afterwards it can
+	// be used again for a new request.
+	IdempotencyKeyTTL = 24 * time.Hour
+	// idempotencyStaleAfter is how long a key may stay IN_PROGRESS before it
+	// is assumed to belong to a crashed request and is recovered.
+	idempotencyStaleAfter = 5 * time.Minute
+)
+
+// NotChargedError wraps an error raised before the provider was asked to
+// charge. Once releases the key instead of failing it, so the request can be
+// retried under the same key.
+type NotChargedError struct {
+	Err error
+}
+
+func (e *NotChargedError) Error() string { return e.Err.Error() }
+func (e *NotChargedError) Unwrap() error { return e.Err }
+
+// idempotencyAction is what a request does with its key.
+type idempotencyAction int
+
+const (
+	idempotencyRun     idempotencyAction = iota // claim the key and charge
+	idempotencyReplay                           // return the stored response
+	idempotencyReused                           // key belongs to another request
+	idempotencyBusy                             // first request still running
+	idempotencyFail                             // first request failed
+	idempotencyRecover                          // first request crashed; check its payment
+)
+
+// idempotencyKey is a stored key as read under lock.
+type idempotencyKey struct {
+	Hash      string
+	Status    string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// resolveIdempotencyKey decides what a request with hash does with the
+// stored key k, or with a key nobody holds when k is nil. Expired keys are
+// free again.
+func resolveIdempotencyKey(k *idempotencyKey, hash string, now time.Time) idempotencyAction {
+	switch {
+	case k == nil || !now.Before(k.ExpiresAt):
+		return idempotencyRun
+	case k.Hash != hash:
+		return idempotencyReused
+	case k.Status == idempotencyCompleted:
+		return idempotencyReplay
+	case k.Status == idempotencyFailed:
+		return idempotencyFail
+	case now.Sub(k.CreatedAt) >= idempotencyStaleAfter:
+		return idempotencyRecover
+	}
+	return idempotencyBusy
+}
+
+// recoveredKey decides how a stale IN_PROGRESS key ends from the payment
+// its request recorded, or nil if it recorded none. Without a payment
+// nothing was charged and the key is claimed again. A payment the provider
+// never answered (PENDING) or that failed may or may not have been charged,
+// so the key fails. Otherwise the key completes with the response the
+// request would have returned.
+func recoveredKey(p *dtos.PaymentResponse) (action idempotencyAction, body []byte) {
+	switch {
+	case p == nil:
+		return idempotencyRun, nil
+	case p.Status == "PENDING" || p.Status == "FAILED":
+		return idempotencyFail, nil
+	}
+	body, _ = json.Marshal(p)
+	return idempotencyReplay, body
+}
+
+type IdempotencyRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewIdempotencyRepository(pool *pgxpool.Pool) *IdempotencyRepository { return &IdempotencyRepository{pool: pool} }
+
+// Once runs fn at most once per user and key and returns its stored
+// response to every later call with the same request hash. The key is
+// committed IN_PROGRESS before fn runs and finalized with fn's response
+// afterwards, so the claim survives a crash mid-charge. A key whose attempt
+// is still running or failed is not run again: later calls get
+// ErrIdempotencyKeyInProgress or ErrIdempotencyKeyFailed, and a different
+// hash gets ErrIdempotencyKeyReused. If fn fails with a NotChargedError the
+// key is released instead. A key left IN_PROGRESS for longer than a request
+// can take is recovered from the payment recorded under it. replayed is
+// true when fn didn't run.
+func (r *IdempotencyRepository) Once(ctx context.Context, userID, key, hash string, fn func() (status int, body []byte, paymentID string, err error)) (status int, body []byte, replayed bool, err error) {
+	action, status, body, err := r.claim(ctx, userID, key, hash, time.Now())
+	switch {
+	case err != nil:
+		return 0, nil, false, err
+	case action == idempotencyReplay:
+		return status, body, true, nil
+	case action == idempotencyReused:
+		return 0, nil, false, ErrIdempotencyKeyReused
+	case action == idempotencyBusy:
+		return 0, nil, false, ErrIdempotencyKeyInProgress
+	case action == idempotencyFail:
+		return 0, nil, false, ErrIdempotencyKeyFailed
+	}
+
+	status, body, paymentID, err := fn()
+	// the request context may be done by now; the key must still be finalized
+	finalizeCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var notCharged *NotChargedError
+	switch {
+	case errors.As(err, &notCharged):
+		sql := `DELETE FROM payment_idempotency_keys WHERE user_id = $1 AND key = $2 AND status = $3`
+		if _, ferr := r.pool.Exec(finalizeCtx, sql, userID, key, idempotencyInProgress); ferr != nil {
+			return 0, nil, false, errors.Join(err, ferr)
+		}
+		return 0, nil, false, err
+	case err != nil:
+		sql := `UPDATE payment_idempotency_keys SET status = $3, error = $4 WHERE user_id = $1 AND key = $2`
+		if _, ferr := r.pool.Exec(finalizeCtx, sql, userID, key, idempotencyFailed, err.Error()); ferr != nil {
+			return 0, nil, false, errors.Join(err, ferr)
+		}
+		return 0, nil, false, err
+	}
+	sql := `UPDATE payment_idempotency_keys SET status = $3, payment_id = NULLIF($4, '')::uuid, response_status = $5, response_body = $6
+	WHERE user_id = $1 AND key = $2`
+	if _, err := r.pool.Exec(finalizeCtx, sql, userID, key, idempotencyCompleted, paymentID, status, body); err != nil {
+		return 0, nil, false, err
+	}
+	return status, body, false, nil
+}
+
+// claim locks the key and resolves what the request does with it. A key
+// that is free, expired or recovered without a payment is (re)claimed
+// IN_PROGRESS for hash before the transaction commits.
+func (r *IdempotencyRepository) claim(ctx context.Context, userID, key, hash string, now time.Time) (idempotencyAction, int, []byte, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	sql := `INSERT INTO payment_idempotency_keys (user_id, key, request_hash, status, created_at, expires_at)
+	VALUES ($1,$2,$3,$4,$5,$6)
+	ON CONFLICT (user_id, key) DO NOTHING`
+	tag, err := tx.Exec(ctx, sql, userID, key, hash, idempotencyInProgress, now, now.Add(IdempotencyKeyTTL))
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	if tag.RowsAffected() == 1 {
+		return idempotencyRun, 0, nil, tx.Commit(ctx)
+	}
+
+	var k idempotencyKey
+	var status int
+	var body []byte
+	sql = `SELECT request_hash, status, created_at, expires_at, response_status, response_body
+	FROM payment_idempotency_keys WHERE user_id = $1 AND key = $2 FOR UPDATE`
+	if err := tx.QueryRow(ctx, sql, userID, key).Scan(&k.Hash, &k.Status, &k.CreatedAt, &k.ExpiresAt, &status, &body); err != nil {
+		return 0, 0, nil, err
+	}
+	action := resolveIdempotencyKey(&k, hash, now)
+	if action == idempotencyRecover {
+		p, err := recordedPayment(ctx, tx, userID, key, k.CreatedAt)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		if action, body = recoveredKey(p); action == idempotencyReplay {
+			status = 201
+			sql = `UPDATE payment_idempotency_keys SET status = $3, payment_id = $4, response_status = $5, response_body = $6
+			WHERE user_id = $1 AND key = $2`
+			if _, err := tx.Exec(ctx, sql, userID, key, idempotencyCompleted, p.PaymentID, status, body); err != nil {
+				return 0, 0, nil, err
+			}
+		} else if action == idempotencyFail {
+			sql = `UPDATE payment_idempotency_keys SET status = $3, error = $4 WHERE user_id = $1 AND key = $2`
+			if _, err := tx.Exec(ctx, sql, userID, key, idempotencyFailed, "request did not finish; the charge may have gone through"); err != nil {
+				return 0, 0, nil, err
+			}
+		}
+	}
+	if action == idempotencyRun {
+		sql = `UPDATE payment_idempotency_keys SET request_hash = $3, status = $4, payment_id = NULL, response_status = 0,
+			response_body = NULL, error = NULL, created_at = $5, expires_at = $6
+		WHERE user_id = $1 AND key = $2`
+		if _, err := tx.Exec(ctx, sql, userID, key, hash, idempotencyInProgress, now, now.Add(IdempotencyKeyTTL)); err != nil {
+			return 0, 0, nil, err
+		}
+	}
+	return action, status, body, tx.Commit(ctx)
+}
+
+// recordedPayment returns the latest payment created under the key since it
+// was claimed at since, or nil if there is none.
+func recordedPayment(ctx context.Context, tx pgx.Tx, userID, key string, since time.Time) (*dtos.PaymentResponse, error) {
+	var p dtos.PaymentResponse
+	var txID *string
+	sql := `SELECT id, status, transaction_id, created_at FROM payments
+	WHERE user_id = $1 AND idempotency_key = $2 AND created_at >= $3
+	ORDER BY created_at DESC LIMIT 1`
+	err := tx.QueryRow(ctx, sql, userID, key, since).Scan(&p.PaymentID, &p.Status, &txID, &p.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if txID != nil {
+		p.ProviderTxID = *txID
+	}
+	return &p, nil
+}
+
+// DeleteExpired forgets keys whose TTL has passed and returns how many.
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM payment_idempotency_keys WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
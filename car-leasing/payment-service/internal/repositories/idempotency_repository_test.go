@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"leaseCar/payment-service/internal/dtos"
+)
+
+func TestResolveIdempotencyKey(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	key := func(hash, status string, age time.Duration) *idempotencyKey {
+		created := now.Add(-age)
+		return &idempotencyKey{Hash: hash, Status: status, CreatedAt: created, ExpiresAt: created.Add(IdempotencyKeyTTL)}
+	}
+	tests := []struct {
+		name string
+		key  *idempotencyKey
+		hash string
+		want idempotencyAction
+	}{
+		{name: "new key", key: nil, hash: "a", want: idempotencyRun},
+		{name: "completed, same request", key: key("a", idempotencyCompleted, time.Minute), hash: "a", want: idempotencyReplay},
+		{name: "completed, other request", key: key("a", idempotencyCompleted, time.Minute), hash: "b", want: idempotencyReused},
+		{name: "in progress", key: key("a", idempotencyInProgress, time.Second), hash: "a", want: idempotencyBusy},
+		{name: "in progress, other request", key: key("a", idempotencyInProgress, time.Second), hash: "b", want: idempotencyReused},
+		{name: "in progress, stale", key: key("a", idempotencyInProgress, idempotencyStaleAfter), hash: "a", want: idempotencyRecover},
+		{name: "failed", key: key("a", idempotencyFailed, time.Hour), hash: "a", want: idempotencyFail},
+		{name: "expired completed", key: key("a", idempotencyCompleted, IdempotencyKeyTTL), hash: "a", want: idempotencyRun},
+		{name: "expired, other request", key: key("a", idempotencyFailed, IdempotencyKeyTTL+time.Hour), hash: "b", want: idempotencyRun},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveIdempotencyKey(tt.key, tt.hash, now); got != tt.want {
+				t.Errorf("resolveIdempotencyKey = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecoveredKey(t *testing.T) {
+	created := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	payment := func(status string) *dtos.PaymentResponse {
+		return &dtos.PaymentResponse{PaymentID: "7f1c0d7e-5b51-4c1b-9d2b-2b7e8f0a9c11", Status: status, ProviderTxID: "stripe_tx_1", CreatedAt: created}
+	}
+	tests := []struct {
+		name    string
+		payment *dtos.PaymentResponse
+		want    idempotencyAction
+	}{
+		{name: "nothing recorded", want: idempotencyRun},
+		{name: "provider never answered", payment: payment("PENDING"), want: idempotencyFail},
+		{name: "charge failed", payment: payment("FAILED"), want: idempotencyFail},
+		{name: "completed", payment: payment("COMPLETED"), want: idempotencyReplay},
+		{name: "processing", payment: payment("PROCESSING"), want: idempotencyReplay},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, body := recoveredKey(tt.payment)
+			if got != tt.want {
+				t.Fatalf("recoveredKey = %d, want %d", got, tt.want)
+			}
+			if got != idempotencyReplay {
+				if body != nil {
+					t.Errorf("body = %s, want none", body)
+				}
+				return
+			}
+			var resp dtos.PaymentResponse
+			if err := json.Unmarshal(body, &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp != *tt.payment {
+				t.Errorf("replayed %+v, want %+v", resp, *tt.payment)
+			}
+		})
+	}
+}
+
+func TestNotChargedError(t *testing.T) {
+	cause := errors.New("card number missing")
+	err := fmt.Errorf("create payment: %w", &NotChargedError{Err: cause})
+	var notCharged *NotChargedError
+	if !errors.As(err, &notCharged) {
+		t.Fatal("NotChargedError not found through wrapping")
+	}
+	if !errors.Is(err, cause) || notCharged.Error() != cause.Error() {
+		t.Errorf("NotChargedError hides its cause: %v", err)
+	}
+}
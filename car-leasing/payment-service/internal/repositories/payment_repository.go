@@ -4,11 +4,16 @@ import (
 	"context"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/google/uuid"
 	"leaseCar/payment-service/internal/dtos"
 )
 
+const paymentColumns = `id, lease_id, lease_payment_id, user_id, amount, refunded_amount, COALESCE(currency, 'USD'), status, method, provider,
+	transaction_id, blockchain_tx_hash, error_message, created_at, COALESCE(updated_at, created_at), completed_at,
+	cancellation_reason, cancelled_at, reversal_status, reversal_method, reversal_attempts`
+
 type PaymentRepository struct {
 	pool *pgxpool.Pool
 }
@@ -17,17 +22,200 @@ func NewPaymentRepository(pool *pgxpool.Pool) *PaymentRepository { return &Payme
 
 func (r *PaymentRepository) Create(ctx context.Context, req *dtos.PaymentRequest) (string, error) {
 	id := uuid.New().String()
-	sql := `INSERT INTO payments (id, lease_id, lease_payment_id, user_id, amount, currency, status, method, provider, created_at)
-	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`
-	_, err := r.pool.Exec(ctx, sql, id, req.LeaseID, req.LeasePaymentID, req.UserID, req.Amount, req.Currency, "PENDING", req.Method, req.Provider, time.Now())
+	sql := `INSERT INTO payments (id, lease_id, lease_payment_id, user_id, amount, currency, status, method, provider, idempotency_key, created_at)
+	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,NULLIF($10, ''),$11)`
+	_, err := r.pool.Exec(ctx, sql, id, req.LeaseID, req.LeasePaymentID, req.UserID, req.Amount, req.Currency, "PENDING", req.Method, req.Provider, req.IdempotencyKey, time.Now())
 	if err != nil {
 		return "", err
 	}
 	return id, nil
 }
 
-func (r *PaymentRepository) UpdateStatus(ctx context.Context, id, status, txHash string) error {
-	sql := `UPDATE payments SET status=$1, transaction_id=$2, updated_at=$3 WHERE id=$4`
-	_, err := r.pool.Exec(ctx, sql, status, txHash, time.Now(), id)
+// UpdateStatus records the provider's answer to a charge and reports
+// whether it was applied. Only PENDING and PROCESSING payments move, so a
+// late answer cannot overwrite a payment a webhook has already settled or
+// one cancelled meanwhile; for those only the provider transaction id is
+// kept and applied is false. If the payment was cancelled and reversal
+// names how to undo the charge (REFUND or VOID), the reversal is queued as
+// PENDING in the same transaction. A payment that moves to COMPLETED settles
+// its lease_payment in the same transaction.
+func (r *PaymentRepository) UpdateStatus(ctx context.Context, id, status, txHash, reversal string) (bool, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+	sql := `UPDATE payments SET status=$1, transaction_id=$2, updated_at=$3,
+	completed_at = CASE WHEN $1 = 'COMPLETED' THEN $3 ELSE completed_at END
+	WHERE id=$4 AND status IN ('PENDING', 'PROCESSING')`
+	tag, err := tx.Exec(ctx, sql, status, txHash, now, id)
+	if err != nil {
+		return false, err
+	}
+	applied := tag.RowsAffected() == 1
+	if !applied {
+		sql = `UPDATE payments SET transaction_id = COALESCE(transaction_id, NULLIF($2, '')),
+			reversal_status = CASE WHEN status = 'CANCELLED' AND $4 <> '' AND reversal_status IS NULL THEN 'PENDING' ELSE reversal_status END,
+			reversal_method = CASE WHEN status = 'CANCELLED' AND $4 <> '' AND reversal_status IS NULL THEN $4 ELSE reversal_method END,
+			updated_at = $3
+		WHERE id = $1`
+		if _, err := tx.Exec(ctx, sql, id, txHash, now, reversal); err != nil {
+			return false, err
+		}
+	} else if status == dtos.PaymentStatusCompleted {
+		if err := settleLeasePayment(ctx, tx, id, now); err != nil {
+			return false, err
+		}
+	}
+	return applied, tx.Commit(ctx)
+}
+
+// RecordReversal stores the outcome of an attempt to reverse a cancelled
+// payment's charge: the new reversal status and a message in error_message.
+// Only PENDING reversals are updated, so concurrent attempts count once.
+func (r *PaymentRepository) RecordReversal(ctx context.Context, id, status, message string) error {
+	sql := `UPDATE payments SET reversal_status = $2, error_message = $3, reversal_attempts = reversal_attempts + 1,
+		reversal_attempted_at = $4, updated_at = $4
+	WHERE id = $1 AND reversal_status = 'PENDING'`
+	_, err := r.pool.Exec(ctx, sql, id, status, message, time.Now())
+	return err
+}
+
+// ListReversals returns payments whose reversal is in status, least
+// recently attempted first. A non-nil before skips ones attempted since.
+func (r *PaymentRepository) ListReversals(ctx context.Context, status string, before *time.Time, limit int) ([]dtos.Payment, error) {
+	sql := `SELECT ` + paymentColumns + ` FROM payments
+	WHERE reversal_status = $1 AND ($2::timestamp IS NULL OR COALESCE(reversal_attempted_at, updated_at) < $2)
+	ORDER BY COALESCE(reversal_attempted_at, updated_at), id LIMIT $3`
+	return r.queryPayments(ctx, sql, status, before, limit)
+}
+
+// RetryReversal puts a FAILED reversal back to PENDING with its attempts
+// reset. Returns pgx.ErrNoRows when the payment has no failed reversal.
+func (r *PaymentRepository) RetryReversal(ctx context.Context, id string) (*dtos.Payment, error) {
+	sql := `UPDATE payments SET reversal_status = 'PENDING', reversal_attempts = 0, updated_at = $2
+	WHERE id = $1 AND reversal_status = 'FAILED'
+	RETURNING ` + paymentColumns
+	return scanPayment(r.pool.QueryRow(ctx, sql, id, time.Now()))
+}
+
+// settleLeasePayment credits a completed payment to the lease_payments item
+// it was made for: paid_amount grows by the payment and the item becomes
+// PAID once covered, which stops overdue marking and late fees for it. The
+// credit is recorded as the payment's settled_amount so a refund can take it
+// back. Callers run it once, in the transaction that moves the payment to
+// COMPLETED.
+func settleLeasePayment(ctx context.Context, tx pgx.Tx, paymentID string, now time.Time) error {
+	sql := `UPDATE lease_payments lp SET
+		paid_amount = COALESCE(lp.paid_amount, 0) + p.amount,
+		paid_at = $2,
+		status = CASE WHEN COALESCE(lp.paid_amount, 0) + p.amount >= lp.amount THEN 'PAID' ELSE lp.status END,
+		updated_at = $2
+	FROM payments p
+	WHERE p.id = $1 AND lp.id = p.lease_payment_id AND lp.status IN ('PENDING', 'OVERDUE')`
+	tag, err := tx.Exec(ctx, sql, paymentID, now)
+	if err != nil || tag.RowsAffected() == 0 {
+		return err
+	}
+	_, err = tx.Exec(ctx, `UPDATE payments SET settled_amount = amount WHERE id = $1`, paymentID)
+	return err
+}
+
+// unsettleLeasePayment takes a refunded amount back off the lease_payments
+// item the payment was credited to, up to what is still credited. An item
+// no longer covered is reopened as PENDING, and the scheduler marks it
+// OVERDUE again if it is past due. Callers run it in the transaction that
+// records the refund.
+func unsettleLeasePayment(ctx context.Context, tx pgx.Tx, paymentID string, amount float64, now time.Time) error {
+	var leasePaymentID *string
+	var settled float64
+	sql := `SELECT lease_payment_id, settled_amount FROM payments WHERE id = $1 FOR UPDATE`
+	if err := tx.QueryRow(ctx, sql, paymentID).Scan(&leasePaymentID, &settled); err != nil {
+		return err
+	}
+	amount = min(amount, settled)
+	if leasePaymentID == nil || amount <= 0 {
+		return nil
+	}
+	if _, err := tx.Exec(ctx, `UPDATE payments SET settled_amount = settled_amount - $2 WHERE id = $1`, paymentID, amount); err != nil {
+		return err
+	}
+	sql = `UPDATE lease_payments SET
+		paid_amount = GREATEST(COALESCE(paid_amount, 0) - $2, 0),
+		paid_at = CASE WHEN COALESCE(paid_amount, 0) - $2 <= 0 THEN NULL ELSE paid_at END,
+		status = CASE WHEN status = 'PAID' AND COALESCE(paid_amount, 0) - $2 < amount THEN 'PENDING' ELSE status END,
+		updated_at = $3
+	WHERE id = $1`
+	_, err := tx.Exec(ctx, sql, *leasePaymentID, amount, now)
 	return err
 }
+
+func (r *PaymentRepository) GetByID(ctx context.Context, id string) (*dtos.Payment, error) {
+	sql := `SELECT ` + paymentColumns + ` FROM payments WHERE id = $1`
+	return scanPayment(r.pool.QueryRow(ctx, sql, id))
+}
+
+// ListByLease returns every payment made against a lease, newest first.
+func (r *PaymentRepository) ListByLease(ctx context.Context, leaseID string) ([]dtos.Payment, error) {
+	sql := `SELECT ` + paymentColumns + ` FROM payments WHERE lease_id = $1 ORDER BY created_at DESC, id DESC`
+	return r.queryPayments(ctx, sql, leaseID)
+}
+
+// ListByUser returns a user's payments newest first, optionally limited to
+// a status and a [from, to) creation window. When afterCreatedAt is set,
+// only payments strictly after the (afterCreatedAt, afterID) key in that
+// order are returned.
+func (r *PaymentRepository) ListByUser(ctx context.Context, userID, status string, from, to, afterCreatedAt *time.Time, afterID string, limit int) ([]dtos.Payment, error) {
+	sql := `SELECT ` + paymentColumns + ` FROM payments
+	WHERE user_id = $1
+	  AND ($2 = '' OR status::text = $2)
+	  AND ($3::timestamp IS NULL OR created_at >= $3)
+	  AND ($4::timestamp IS NULL OR created_at < $4)
+	  AND ($5::timestamp IS NULL OR (created_at, id) < ($5, $6::uuid))
+	ORDER BY created_at DESC, id DESC
+	LIMIT $7`
+	if afterCreatedAt == nil {
+		afterID = "00000000-0000-0000-0000-000000000000"
+	}
+	return r.queryPayments(ctx, sql, userID, status, from, to, afterCreatedAt, afterID, limit)
+}
+
+func (r *PaymentRepository) queryPayments(ctx context.Context, sql string, args ...interface{}) ([]dtos.Payment, error) {
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := []dtos.Payment{}
+	for rows.Next() {
+		p, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, *p)
+	}
+	return payments, rows.Err()
+}
+
+func scanPayment(row pgx.Row) (*dtos.Payment, error) {
+	var p dtos.Payment
+	err := row.Scan(&p.ID, &p.LeaseID, &p.LeasePaymentID, &p.UserID, &p.Amount, &p.RefundedAmount, &p.Currency, &p.Status, &p.Method, &p.Provider,
+		&p.TransactionID, &p.BlockchainTxHash, &p.ErrorMessage, &p.CreatedAt, &p.UpdatedAt, &p.CompletedAt,
+		&p.CancellationReason, &p.CancelledAt, &p.ReversalStatus, &p.ReversalMethod, &p.ReversalAttempts)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Cancel moves a PENDING or PROCESSING payment to CANCELLED. Returns
+// pgx.ErrNoRows when the payment is gone or no longer in either status.
+func (r *PaymentRepository) Cancel(ctx context.Context, id, reason string) (*dtos.Payment, error) {
+	sql := `UPDATE payments SET status = 'CANCELLED', cancellation_reason = $2, cancelled_at = $3, updated_at = $3
+	WHERE id = $1 AND status IN ('PENDING', 'PROCESSING')
+	RETURNING ` + paymentColumns
+	return scanPayment(r.pool.QueryRow(ctx, sql, id, reason, time.Now()))
+}
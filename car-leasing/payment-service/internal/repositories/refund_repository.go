@@ -0,0 +1,165 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"leaseCar/payment-service/internal/dtos"
+)
+
+const refundColumns = `id, payment_id, amount, currency, status, COALESCE(reason, ''), provider_refund_id, error_message, created_at, completed_at`
+
+type RefundRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRefundRepository(pool *pgxpool.Pool) *RefundRepository { return &RefundRepository{pool: pool} }
+
+// Reserve records a PENDING refund and adds it to the payment's
+// refunded_amount while the payment row is locked, so concurrent refunds
+// see each other. amountFor checks the locked payment and returns the
+// amount to refund. Returns pgx.ErrNoRows for an unknown payment.
+func (r *RefundRepository) Reserve(ctx context.Context, paymentID, reason string, amountFor func(*dtos.Payment) (float64, error)) (*dtos.Refund, *dtos.Payment, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	p, err := scanPayment(tx.QueryRow(ctx, `SELECT `+paymentColumns+` FROM payments WHERE id = $1 FOR UPDATE`, paymentID))
+	if err != nil {
+		return nil, nil, err
+	}
+	amount, err := amountFor(p)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sql := `INSERT INTO payment_refunds (payment_id, amount, currency, status, reason, created_at)
+	VALUES ($1,$2,$3,$4,NULLIF($5, ''),$6)
+	RETURNING ` + refundColumns
+	ref, err := scanRefund(tx.QueryRow(ctx, sql, paymentID, amount, p.Currency, dtos.RefundPending, reason, time.Now()))
+	if err != nil {
+		return nil, nil, err
+	}
+	sql = `UPDATE payments SET refunded_amount = refunded_amount + $2, updated_at = $3 WHERE id = $1`
+	if _, err := tx.Exec(ctx, sql, paymentID, amount, time.Now()); err != nil {
+		return nil, nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+	p.RefundedAmount += amount
+	return ref, p, nil
+}
+
+// Complete marks a pending refund SUCCEEDED and takes the refunded amount
+// back off the lease_payments item the payment settled. The payment becomes
+// REFUNDED once succeeded refunds cover its whole amount.
+func (r *RefundRepository) Complete(ctx context.Context, refundID, providerRefundID string) (*dtos.Refund, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+	sql := `UPDATE payment_refunds SET status = $2, provider_refund_id = $3, completed_at = $4
+	WHERE id = $1 AND status = 'PENDING'
+	RETURNING ` + refundColumns
+	ref, err := scanRefund(tx.QueryRow(ctx, sql, refundID, dtos.RefundSucceeded, providerRefundID, now))
+	if err != nil {
+		return nil, err
+	}
+	if err := unsettleLeasePayment(ctx, tx, ref.PaymentID, ref.Amount, now); err != nil {
+		return nil, err
+	}
+	sql = `UPDATE payments SET status = 'REFUNDED', updated_at = $2
+	WHERE id = $1 AND status <> 'REFUNDED'
+	  AND amount <= (SELECT COALESCE(SUM(amount), 0) FROM payment_refunds WHERE payment_id = $1 AND status = 'SUCCEEDED')`
+	if _, err := tx.Exec(ctx, sql, ref.PaymentID, now); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return ref, nil
+}
+
+// Submitted records that the provider accepted a refund without settling
+// it yet. The refund stays PENDING, with its amount reserved, until the
+// reconciler sees the provider's final answer.
+func (r *RefundRepository) Submitted(ctx context.Context, refundID, providerRefundID string) (*dtos.Refund, error) {
+	sql := `UPDATE payment_refunds SET provider_refund_id = NULLIF($2, ''), attempted_at = $3
+	WHERE id = $1 AND status = 'PENDING'
+	RETURNING ` + refundColumns
+	return scanRefund(r.pool.QueryRow(ctx, sql, refundID, providerRefundID, time.Now()))
+}
+
+// ListStale returns PENDING refunds last sent to the provider before
+// before, oldest first.
+func (r *RefundRepository) ListStale(ctx context.Context, before time.Time, limit int) ([]dtos.Refund, error) {
+	sql := `SELECT ` + refundColumns + ` FROM payment_refunds
+	WHERE status = 'PENDING' AND COALESCE(attempted_at, created_at) < $1
+	ORDER BY COALESCE(attempted_at, created_at) LIMIT $2`
+	return r.queryRefunds(ctx, sql, before, limit)
+}
+
+// Fail marks a pending refund FAILED and releases its amount.
+func (r *RefundRepository) Fail(ctx context.Context, refundID, message string) (*dtos.Refund, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	sql := `UPDATE payment_refunds SET status = $2, error_message = $3, completed_at = $4
+	WHERE id = $1 AND status = 'PENDING'
+	RETURNING ` + refundColumns
+	ref, err := scanRefund(tx.QueryRow(ctx, sql, refundID, dtos.RefundFailed, message, time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	sql = `UPDATE payments SET refunded_amount = refunded_amount - $2, updated_at = $3 WHERE id = $1`
+	if _, err := tx.Exec(ctx, sql, ref.PaymentID, ref.Amount, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return ref, nil
+}
+
+func (r *RefundRepository) ListByPayment(ctx context.Context, paymentID string) ([]dtos.Refund, error) {
+	return r.queryRefunds(ctx, `SELECT `+refundColumns+` FROM payment_refunds WHERE payment_id = $1 ORDER BY created_at, id`, paymentID)
+}
+
+func (r *RefundRepository) queryRefunds(ctx context.Context, sql string, args ...interface{}) ([]dtos.Refund, error) {
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refunds := []dtos.Refund{}
+	for rows.Next() {
+		ref, err := scanRefund(rows)
+		if err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, *ref)
+	}
+	return refunds, rows.Err()
+}
+
+func scanRefund(row pgx.Row) (*dtos.Refund, error) {
+	var ref dtos.Refund
+	err := row.Scan(&ref.ID, &ref.PaymentID, &ref.Amount, &ref.Currency, &ref.Status, &ref.Reason,
+		&ref.ProviderRefundID, &ref.ErrorMessage, &ref.CreatedAt, &ref.CompletedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &ref, nil
+}
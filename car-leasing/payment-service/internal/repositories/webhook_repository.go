@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"leaseCar/payment-service/internal/dtos"
+)
+
+type WebhookRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewWebhookRepository(pool *pgxpool.Pool) *WebhookRepository { return &WebhookRepository{pool: pool} }
+
+// Save stores a verified event in the inbox. A redelivered event keeps its
+// original row; processed reports whether that row was already applied.
+func (r *WebhookRepository) Save(ctx context.Context, ev *dtos.WebhookEvent) (id string, processed bool, err error) {
+	sql := `INSERT INTO payment_webhooks (provider, provider_event_id, event_type, event_data, created_at)
+	VALUES ($1,$2,$3,$4,$5)
+	ON CONFLICT (provider, provider_event_id) DO NOTHING
+	RETURNING id`
+	err = r.pool.QueryRow(ctx, sql, strings.ToUpper(ev.Provider), ev.EventID, ev.EventType, ev.Data, time.Now()).Scan(&id)
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return id, false, err
+	}
+	sql = `SELECT id, COALESCE(processed, false) FROM payment_webhooks WHERE provider = $1 AND provider_event_id = $2`
+	err = r.pool.QueryRow(ctx, sql, strings.ToUpper(ev.Provider), ev.EventID).Scan(&id, &processed)
+	return id, processed, err
+}
+
+// Apply processes an inbox row exactly once: it resolves the payment by
+// id or provider transaction id, moves it to ev.Status when its current
+// status is in from, and marks the row processed. A refund event only
+// moves the payment to REFUNDED once refunded_amount covers it, so a
+// partial refund leaves it COMPLETED. It returns pgx.ErrNoRows, after
+// recording the error on the row, when the payment isn't known yet;
+// already is true if another delivery got there first.
+func (r *WebhookRepository) Apply(ctx context.Context, webhookID string, ev *dtos.WebhookEvent, from []string) (*dtos.WebhookResult, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	res := &dtos.WebhookResult{WebhookID: webhookID, EventID: ev.EventID}
+	var processed bool
+	var paymentID *string
+	err = tx.QueryRow(ctx, `SELECT COALESCE(processed, false), payment_id FROM payment_webhooks WHERE id = $1 FOR UPDATE`, webhookID).
+		Scan(&processed, &paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if processed {
+		res.Duplicate = true
+		if paymentID != nil {
+			res.PaymentID = *paymentID
+		}
+		return res, nil
+	}
+
+	now := time.Now()
+	sql := `SELECT id, status FROM payments
+	WHERE provider = $1 AND (id::text = $2 OR ($3 <> '' AND transaction_id = $3))
+	ORDER BY (id::text = $2) DESC LIMIT 1
+	FOR UPDATE`
+	err = tx.QueryRow(ctx, sql, strings.ToUpper(ev.Provider), ev.PaymentID, ev.TransactionID).Scan(&res.PaymentID, &res.Status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if _, err := tx.Exec(ctx, `UPDATE payment_webhooks SET error = $2 WHERE id = $1`, webhookID, "payment not found"); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return nil, err
+		}
+		return nil, pgx.ErrNoRows
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if ev.Status != "" {
+		sql = `UPDATE payments SET status = $2, updated_at = $3,
+		completed_at = CASE WHEN $2 = 'COMPLETED' THEN $3 ELSE completed_at END
+		WHERE id = $1 AND status::text = ANY($4)
+		AND ($2 <> 'REFUNDED' OR refunded_amount >= amount)`
+		tag, err := tx.Exec(ctx, sql, res.PaymentID, ev.Status, now, from)
+		if err != nil {
+			return nil, err
+		}
+		if tag.RowsAffected() == 1 {
+			res.Status = ev.Status
+			res.Applied = true
+			if ev.Status == dtos.PaymentStatusCompleted {
+				if err := settleLeasePayment(ctx, tx, res.PaymentID, now); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	sql = `UPDATE payment_webhooks SET payment_id = $2, processed = true, processed_at = $3, error = NULL WHERE id = $1`
+	if _, err := tx.Exec(ctx, sql, webhookID, res.PaymentID, now); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"leaseCar/payment-service/internal/dtos"
+	"leaseCar/utils/logger"
+)
+
+const MaxCancelReasonLength = 500
+
+var ErrInvalidCancelReason = fmt.Errorf("reason is required and must be at most %d characters", MaxCancelReasonLength)
+
+// PaymentNotCancellableError reports a payment that is past the point
+// where it can be cancelled.
+type PaymentNotCancellableError struct {
+	Status string
+}
+
+func (e *PaymentNotCancellableError) Error() string {
+	if e.Status == dtos.PaymentStatusCompleted {
+		return "payment is COMPLETED; refund it instead"
+	}
+	return "payment is " + e.Status + "; only PENDING or PROCESSING payments can be cancelled"
+}
+
+// CancelPayment cancels a PENDING or PROCESSING payment. When the provider
+// already has the charge or transfer it is asked to void it first; if it
+// can't, the payment is left as it was.
+func (s *PaymentService) CancelPayment(ctx context.Context, id string, in *dtos.CancelRequest) (*dtos.Payment, error) {
+	reason := strings.TrimSpace(in.Reason)
+	if reason == "" || len(reason) > MaxCancelReasonLength {
+		return nil, ErrInvalidCancelReason
+	}
+	p, err := s.GetPayment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != dtos.PaymentStatusPending && p.Status != dtos.PaymentStatusProcessing {
+		return nil, &PaymentNotCancellableError{Status: p.Status}
+	}
+
+	if p.TransactionID != nil && *p.TransactionID != "" {
+		strat := s.factory.GetStrategy(strings.ToLower(p.Provider))
+		if strat == nil {
+			return nil, &ProviderError{Err: fmt.Errorf("no strategy for provider %s", p.Provider)}
+		}
+		req := &dtos.ProviderVoidRequest{PaymentID: p.ID, ProviderTxID: *p.TransactionID, Reason: reason}
+		if err := strat.Void(ctx, req); err != nil {
+			return nil, &ProviderError{Err: err}
+		}
+	}
+
+	// guarded on status: a webhook may have settled the payment meanwhile
+	cancelled, err := s.repo.Cancel(ctx, id, reason)
+	if errors.Is(err, pgx.ErrNoRows) {
+		current, err := s.GetPayment(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return nil, &PaymentNotCancellableError{Status: current.Status}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	event := dtos.PaymentCancelledEvent{Event: "payment.cancelled", PaymentID: cancelled.ID, LeaseID: cancelled.LeaseID, LeasePaymentID: cancelled.LeasePaymentID, Amount: cancelled.Amount, Reason: reason, Status: cancelled.Status}
+	b, _ := json.Marshal(event)
+	if err := s.redisClient.Publish(context.Background(), "payments", string(b)); err != nil {
+		logger.Error("failed to publish payment cancellation event")
+	}
+	return cancelled, nil
+}
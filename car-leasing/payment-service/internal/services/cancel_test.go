@@ -0,0 +1,367 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"leaseCar/payment-service/internal/dtos"
+	"leaseCar/payment-service/internal/strategies"
+	"leaseCar/payment-service/internal/webhooks"
+)
+
+// fakeStore keeps payments in memory and applies the same status guards as
+// PaymentRepository. Methods the tests don't need panic through the nil
+// embedded interface.
+type fakeStore struct {
+	paymentStore
+	payments map[string]*dtos.Payment
+	// beforeCancel runs before Cancel, to change the payment under it
+	beforeCancel func(p *dtos.Payment)
+}
+
+func newFakeStore(payments ...*dtos.Payment) *fakeStore {
+	s := &fakeStore{payments: map[string]*dtos.Payment{}}
+	for _, p := range payments {
+		s.payments[p.ID] = p
+	}
+	return s
+}
+
+func movable(status string) bool {
+	return status == dtos.PaymentStatusPending || status == dtos.PaymentStatusProcessing
+}
+
+func (s *fakeStore) Create(ctx context.Context, req *dtos.PaymentRequest) (string, error) {
+	id := fmt.Sprintf("00000000-0000-4000-8000-%012d", len(s.payments)+1)
+	s.payments[id] = &dtos.Payment{ID: id, Amount: req.Amount, Currency: req.Currency, Status: dtos.PaymentStatusPending, Provider: req.Provider}
+	return id, nil
+}
+
+func (s *fakeStore) UpdateStatus(ctx context.Context, id, status, txHash, reversal string) (bool, error) {
+	p := s.payments[id]
+	if movable(p.Status) {
+		p.Status, p.TransactionID = status, &txHash
+		return true, nil
+	}
+	if p.TransactionID == nil && txHash != "" {
+		p.TransactionID = &txHash
+	}
+	if p.Status == dtos.PaymentStatusCancelled && reversal != "" && p.ReversalStatus == nil {
+		pending := dtos.ReversalPending
+		p.ReversalStatus, p.ReversalMethod = &pending, &reversal
+	}
+	return false, nil
+}
+
+func (s *fakeStore) GetByID(ctx context.Context, id string) (*dtos.Payment, error) {
+	p, ok := s.payments[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	cp := *p
+	return &cp, nil
+}
+
+func (s *fakeStore) Cancel(ctx context.Context, id, reason string) (*dtos.Payment, error) {
+	p, ok := s.payments[id]
+	if ok && s.beforeCancel != nil {
+		s.beforeCancel(p)
+	}
+	if !ok || !movable(p.Status) {
+		return nil, pgx.ErrNoRows
+	}
+	now := time.Now()
+	p.Status, p.CancellationReason, p.CancelledAt = dtos.PaymentStatusCancelled, &reason, &now
+	return s.GetByID(ctx, id)
+}
+
+func (s *fakeStore) RecordReversal(ctx context.Context, id, status, message string) error {
+	p := s.payments[id]
+	if p.ReversalStatus == nil || *p.ReversalStatus != dtos.ReversalPending {
+		return nil
+	}
+	p.ReversalStatus, p.ErrorMessage = &status, &message
+	p.ReversalAttempts++
+	return nil
+}
+
+func (s *fakeStore) ListReversals(ctx context.Context, status string, before *time.Time, limit int) ([]dtos.Payment, error) {
+	var out []dtos.Payment
+	for _, p := range s.payments {
+		if p.ReversalStatus != nil && *p.ReversalStatus == status {
+			out = append(out, *p)
+		}
+	}
+	return out, nil
+}
+
+type fakeStrategy struct {
+	process   func(req *dtos.PaymentRequest) (*dtos.PaymentResponse, error)
+	refundErr error
+	voidErr   error
+	refunds   []dtos.ProviderRefundRequest
+	voids     []dtos.ProviderVoidRequest
+}
+
+func (f *fakeStrategy) Validate(req *dtos.PaymentRequest) error { return nil }
+
+func (f *fakeStrategy) Process(ctx context.Context, req *dtos.PaymentRequest) (*dtos.PaymentResponse, error) {
+	return f.process(req)
+}
+
+func (f *fakeStrategy) Refund(ctx context.Context, req *dtos.ProviderRefundRequest) (*dtos.ProviderRefundResponse, error) {
+	f.refunds = append(f.refunds, *req)
+	if f.refundErr != nil {
+		return nil, f.refundErr
+	}
+	return &dtos.ProviderRefundResponse{ProviderRefundID: "re_1", Status: dtos.RefundSucceeded}, nil
+}
+
+func (f *fakeStrategy) Void(ctx context.Context, req *dtos.ProviderVoidRequest) error {
+	f.voids = append(f.voids, *req)
+	return f.voidErr
+}
+
+type fakeFactory struct {
+	strat *fakeStrategy
+}
+
+func (f fakeFactory) GetStrategy(provider string) strategies.PaymentStrategy {
+	if provider != "stripe" {
+		return nil
+	}
+	return f.strat
+}
+
+func (f fakeFactory) GetWebhookProvider(provider string) webhooks.Provider { return nil }
+
+type fakePublisher struct {
+	events []string
+}
+
+func (f *fakePublisher) Publish(ctx context.Context, channel string, message interface{}) error {
+	f.events = append(f.events, message.(string))
+	return nil
+}
+
+// published reports whether an event named event was published.
+func (f *fakePublisher) published(event string) bool {
+	for _, e := range f.events {
+		if strings.Contains(e, `"event":"`+event+`"`) {
+			return true
+		}
+	}
+	return false
+}
+
+func newTestService(store *fakeStore, strat *fakeStrategy) (*PaymentService, *fakePublisher) {
+	pub := &fakePublisher{}
+	return &PaymentService{repo: store, factory: fakeFactory{strat: strat}, redisClient: pub}, pub
+}
+
+const testPaymentID = "5b0f2c9e-8d3a-4f1b-9c6e-2a7d4e8f1b3c"
+
+func TestCancelPayment(t *testing.T) {
+	tx := "bank_tx_1"
+	tests := []struct {
+		name         string
+		status       string
+		txID         *string
+		reason       string
+		voidErr      error
+		beforeCancel func(p *dtos.Payment)
+		wantErr      func(error) bool
+		wantVoid     bool
+		wantStatus   string
+	}{
+		{name: "pending, nothing at the provider", status: dtos.PaymentStatusPending, reason: "duplicate order", wantStatus: dtos.PaymentStatusCancelled},
+		{name: "processing transfer is voided first", status: dtos.PaymentStatusProcessing, txID: &tx, reason: "customer request", wantVoid: true, wantStatus: dtos.PaymentStatusCancelled},
+		{name: "provider refuses the void", status: dtos.PaymentStatusProcessing, txID: &tx, reason: "customer request", voidErr: errors.New("already settled"),
+			wantErr: func(err error) bool { var pe *ProviderError; return errors.As(err, &pe) }, wantVoid: true, wantStatus: dtos.PaymentStatusProcessing},
+		{name: "completed must be refunded", status: dtos.PaymentStatusCompleted, reason: "customer request",
+			wantErr: func(err error) bool {
+				var nc *PaymentNotCancellableError
+				return errors.As(err, &nc) && strings.Contains(err.Error(), "refund it instead")
+			}, wantStatus: dtos.PaymentStatusCompleted},
+		{name: "webhook completes it meanwhile", status: dtos.PaymentStatusPending, reason: "customer request",
+			beforeCancel: func(p *dtos.Payment) { p.Status = dtos.PaymentStatusCompleted },
+			wantErr: func(err error) bool {
+				var nc *PaymentNotCancellableError
+				return errors.As(err, &nc) && nc.Status == dtos.PaymentStatusCompleted
+			}, wantStatus: dtos.PaymentStatusCompleted},
+		{name: "blank reason", status: dtos.PaymentStatusPending, reason: "   ",
+			wantErr: func(err error) bool { return errors.Is(err, ErrInvalidCancelReason) }, wantStatus: dtos.PaymentStatusPending},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeStore(&dtos.Payment{ID: testPaymentID, Amount: 450, Status: tt.status, Provider: "STRIPE", TransactionID: tt.txID})
+			store.beforeCancel = tt.beforeCancel
+			strat := &fakeStrategy{voidErr: tt.voidErr}
+			svc, pub := newTestService(store, strat)
+
+			p, err := svc.CancelPayment(context.Background(), testPaymentID, &dtos.CancelRequest{Reason: tt.reason})
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Fatalf("CancelPayment: %v", err)
+			case tt.wantErr != nil && !tt.wantErr(err):
+				t.Fatalf("CancelPayment error = %v", err)
+			case tt.wantErr == nil && p.Status != dtos.PaymentStatusCancelled:
+				t.Errorf("returned status %s, want CANCELLED", p.Status)
+			}
+			if got := store.payments[testPaymentID].Status; got != tt.wantStatus {
+				t.Errorf("stored status %s, want %s", got, tt.wantStatus)
+			}
+			if got := len(strat.voids) > 0; got != tt.wantVoid {
+				t.Errorf("voided = %v, want %v", got, tt.wantVoid)
+			}
+			if got := pub.published("payment.cancelled"); got != (tt.wantErr == nil) {
+				t.Errorf("payment.cancelled published = %v, want %v", got, tt.wantErr == nil)
+			}
+		})
+	}
+
+	t.Run("unknown payment", func(t *testing.T) {
+		svc, _ := newTestService(newFakeStore(), &fakeStrategy{})
+		for _, id := range []string{testPaymentID, "not-a-uuid"} {
+			if _, err := svc.CancelPayment(context.Background(), id, &dtos.CancelRequest{Reason: "x"}); !errors.Is(err, ErrPaymentNotFound) {
+				t.Errorf("CancelPayment(%q) = %v, want ErrPaymentNotFound", id, err)
+			}
+		}
+	})
+}
+
+// A payment cancelled while the provider is charging it must not complete:
+// the charge is reversed and the create request fails.
+func TestCreatePaymentCancelledWhileCharging(t *testing.T) {
+	tests := []struct {
+		name          string
+		providerState string
+		refundErr     error
+		wantRefund    bool
+		wantVoid      bool
+		wantReversal  string
+	}{
+		{name: "captured charge is refunded", providerState: dtos.PaymentStatusCompleted, wantRefund: true, wantReversal: dtos.ReversalReversed},
+		{name: "submitted transfer is voided", providerState: dtos.PaymentStatusProcessing, wantVoid: true, wantReversal: dtos.ReversalReversed},
+		{name: "refund fails and stays queued", providerState: dtos.PaymentStatusCompleted, refundErr: errors.New("timeout"), wantRefund: true, wantReversal: dtos.ReversalPending},
+		{name: "failed charge needs nothing", providerState: dtos.PaymentStatusFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeStore()
+			strat := &fakeStrategy{refundErr: tt.refundErr}
+			svc, pub := newTestService(store, strat)
+			strat.process = func(req *dtos.PaymentRequest) (*dtos.PaymentResponse, error) {
+				// the cancel lands while the provider is still working
+				for id := range store.payments {
+					if _, err := svc.CancelPayment(context.Background(), id, &dtos.CancelRequest{Reason: "changed my mind"}); err != nil {
+						t.Fatalf("CancelPayment during charge: %v", err)
+					}
+				}
+				return &dtos.PaymentResponse{Status: tt.providerState, ProviderTxID: "stripe_tx_1", CreatedAt: time.Now()}, nil
+			}
+
+			req := &dtos.PaymentRequest{Amount: 450, Currency: "USD", Provider: "stripe"}
+			if _, err := svc.CreatePayment(context.Background(), req); !errors.Is(err, ErrPaymentCancelled) {
+				t.Fatalf("CreatePayment = %v, want ErrPaymentCancelled", err)
+			}
+			var p *dtos.Payment
+			for _, stored := range store.payments {
+				p = stored
+			}
+			if p.Status != dtos.PaymentStatusCancelled {
+				t.Errorf("status %s, want CANCELLED", p.Status)
+			}
+			if p.TransactionID == nil || *p.TransactionID != "stripe_tx_1" {
+				t.Errorf("provider transaction id not kept: %v", p.TransactionID)
+			}
+			if pub.published("payment.completed") {
+				t.Error("payment.completed published for a cancelled payment")
+			}
+			if got := len(strat.refunds) > 0; got != tt.wantRefund {
+				t.Errorf("refunded = %v, want %v", got, tt.wantRefund)
+			}
+			if tt.wantRefund && (strat.refunds[0].RefundID != p.ID || strat.refunds[0].Amount != 450) {
+				t.Errorf("refund %+v, want the full amount keyed by the payment id", strat.refunds[0])
+			}
+			if got := len(strat.voids) > 0; got != tt.wantVoid {
+				t.Errorf("voided = %v, want %v", got, tt.wantVoid)
+			}
+			got := ""
+			if p.ReversalStatus != nil {
+				got = *p.ReversalStatus
+			}
+			if got != tt.wantReversal {
+				t.Errorf("reversal status %q, want %q", got, tt.wantReversal)
+			}
+		})
+	}
+}
+
+func TestRetryReversalsGivesUp(t *testing.T) {
+	pending, method, tx := dtos.ReversalPending, dtos.ReversalRefund, "stripe_tx_1"
+	store := newFakeStore(&dtos.Payment{ID: testPaymentID, Amount: 450, Status: dtos.PaymentStatusCancelled, Provider: "STRIPE",
+		TransactionID: &tx, ReversalStatus: &pending, ReversalMethod: &method})
+	strat := &fakeStrategy{refundErr: errors.New("card issuer unavailable")}
+	svc, _ := newTestService(store, strat)
+
+	for i := 1; i <= maxReversalAttempts+2; i++ {
+		if _, err := svc.RetryReversals(context.Background()); err != nil {
+			t.Fatalf("RetryReversals: %v", err)
+		}
+	}
+	p := store.payments[testPaymentID]
+	if *p.ReversalStatus != dtos.ReversalFailed || p.ReversalAttempts != maxReversalAttempts {
+		t.Errorf("reversal %s after %d attempts, want FAILED after %d", *p.ReversalStatus, p.ReversalAttempts, maxReversalAttempts)
+	}
+	if len(strat.refunds) != maxReversalAttempts {
+		t.Errorf("%d refund calls, want %d", len(strat.refunds), maxReversalAttempts)
+	}
+	for _, r := range strat.refunds {
+		if r.RefundID != testPaymentID {
+			t.Errorf("retry used refund id %q, want the payment id", r.RefundID)
+		}
+	}
+	if p.ErrorMessage == nil || !strings.Contains(*p.ErrorMessage, "card issuer unavailable") {
+		t.Errorf("error_message %v does not keep the last failure", p.ErrorMessage)
+	}
+}
+
+func TestReversalMethod(t *testing.T) {
+	tests := map[string]string{
+		dtos.PaymentStatusCompleted:  dtos.ReversalRefund,
+		dtos.PaymentStatusProcessing: dtos.ReversalVoid,
+		dtos.PaymentStatusPending:    dtos.ReversalVoid,
+		dtos.PaymentStatusFailed:     "",
+	}
+	for status, want := range tests {
+		if got := reversalMethod(status); got != want {
+			t.Errorf("reversalMethod(%s) = %q, want %q", status, got, want)
+		}
+	}
+}
+
+func TestNextReversalStatus(t *testing.T) {
+	tests := []struct {
+		attempts int
+		outcome  refundOutcome
+		want     string
+	}{
+		{1, refundSucceeded, dtos.ReversalReversed},
+		{maxReversalAttempts, refundSucceeded, dtos.ReversalReversed},
+		{1, refundDeclined, dtos.ReversalPending},
+		{maxReversalAttempts - 1, refundDeclined, dtos.ReversalPending},
+		{maxReversalAttempts, refundDeclined, dtos.ReversalFailed},
+		{maxReversalAttempts + 5, refundInFlight, dtos.ReversalPending},
+	}
+	for _, tt := range tests {
+		if got := nextReversalStatus(tt.attempts, tt.outcome); got != tt.want {
+			t.Errorf("nextReversalStatus(%d, %d) = %s, want %s", tt.attempts, tt.outcome, got, tt.want)
+		}
+	}
+}
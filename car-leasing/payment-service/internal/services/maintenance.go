@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"leaseCar/utils/logger"
+)
+
+// Maintenance runs the payment service's periodic housekeeping. Every task
+// is safe to run on several replicas at once.
+type Maintenance struct {
+	svc      *PaymentService
+	interval time.Duration
+}
+
+func NewMaintenance(svc *PaymentService, interval time.Duration) *Maintenance {
+	return &Maintenance{svc: svc, interval: interval}
+}
+
+// Run performs a pass immediately and then every interval until ctx is done.
+func (m *Maintenance) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		m.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce performs a single pass; failures are logged and retried on the
+// next one.
+func (m *Maintenance) RunOnce(ctx context.Context) {
+	if _, err := m.svc.idempotency.DeleteExpired(ctx, time.Now()); err != nil {
+		logger.Warn("failed to delete expired idempotency keys: " + err.Error())
+	}
+	if _, err := m.svc.ReconcileRefunds(ctx); err != nil {
+		logger.Warn("failed to reconcile pending refunds: " + err.Error())
+	}
+	if _, err := m.svc.RetryReversals(ctx); err != nil {
+		logger.Warn("failed to retry payment reversals: " + err.Error())
+	}
+}
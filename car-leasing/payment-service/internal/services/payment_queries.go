@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"leaseCar/payment-service/internal/dtos"
+)
+
+var ErrPaymentNotFound = errors.New("payment not found")
+
+// InvalidFilterError reports a query parameter the payment list can't use.
+type InvalidFilterError struct {
+	Param  string
+	Reason string
+}
+
+func (e *InvalidFilterError) Error() string { return e.Param + " " + e.Reason }
+
+var paymentStatuses = map[string]bool{
+	dtos.PaymentStatusPending:    true,
+	dtos.PaymentStatusProcessing: true,
+	dtos.PaymentStatusCompleted:  true,
+	dtos.PaymentStatusFailed:     true,
+	dtos.PaymentStatusRefunded:   true,
+	dtos.PaymentStatusCancelled:  true,
+}
+
+func isUUID(s string) bool {
+	_, err := uuid.Parse(s)
+	return err == nil
+}
+
+func (s *PaymentService) GetPayment(ctx context.Context, id string) (*dtos.Payment, error) {
+	if !isUUID(id) {
+		return nil, ErrPaymentNotFound
+	}
+	p, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrPaymentNotFound
+	}
+	return p, err
+}
+
+func (s *PaymentService) ListLeasePayments(ctx context.Context, leaseID string) ([]dtos.Payment, error) {
+	if !isUUID(leaseID) {
+		return nil, &InvalidFilterError{Param: "lease id", Reason: "must be a UUID"}
+	}
+	return s.repo.ListByLease(ctx, leaseID)
+}
+
+// ListUserPayments pages through a user's payments newest first. from and
+// to are inclusive dates (YYYY-MM-DD) on created_at.
+func (s *PaymentService) ListUserPayments(ctx context.Context, in *dtos.PaymentListRequest) (*dtos.PaymentPage, error) {
+	if !isUUID(in.UserID) {
+		return nil, &InvalidFilterError{Param: "user id", Reason: "must be a UUID"}
+	}
+	status := strings.ToUpper(in.Status)
+	if status != "" && !paymentStatuses[status] {
+		return nil, &InvalidFilterError{Param: "status", Reason: "is not a payment status"}
+	}
+	from, err := parseDay("from", in.From, 0)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseDay("to", in.To, 1)
+	if err != nil {
+		return nil, err
+	}
+	if from != nil && to != nil && !to.After(*from) {
+		return nil, &InvalidFilterError{Param: "to", Reason: "must not be before from"}
+	}
+	in.Limit = pageLimit(in.Limit)
+	var afterCreatedAt *time.Time
+	var afterID string
+	if in.Cursor != "" {
+		createdAt, id, err := decodeCursor(in.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		afterCreatedAt, afterID = &createdAt, id
+	}
+
+	// fetch one extra row to know whether another page exists
+	payments, err := s.repo.ListByUser(ctx, in.UserID, status, from, to, afterCreatedAt, afterID, in.Limit+1)
+	if err != nil {
+		return nil, err
+	}
+	page := &dtos.PaymentPage{Payments: payments}
+	if len(payments) > in.Limit {
+		page.Payments = payments[:in.Limit]
+		last := page.Payments[in.Limit-1]
+		page.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+	return page, nil
+}
+
+// pageLimit defaults a missing limit to 20 and caps it at 100.
+func pageLimit(limit int) int {
+	switch {
+	case limit <= 0:
+		return 20
+	case limit > 100:
+		return 100
+	}
+	return limit
+}
+
+// parseDay parses a YYYY-MM-DD filter and shifts it by addDays; "" is no
+// filter.
+func parseDay(param, value string, addDays int) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, &InvalidFilterError{Param: param, Reason: fmt.Sprintf("must be a date (YYYY-MM-DD), got %q", value)}
+	}
+	day = day.AddDate(0, 0, addDays)
+	return &day, nil
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2026, 2, 3, 14, 5, 6, 123456789, time.UTC)
+	id := "3f2b8c1e-9a4d-4e6f-8b7a-1c2d3e4f5a6b"
+	gotAt, gotID, err := decodeCursor(encodeCursor(createdAt, id))
+	if err != nil {
+		t.Fatalf("decodeCursor: %v", err)
+	}
+	if !gotAt.Equal(createdAt) || gotID != id {
+		t.Errorf("decoded (%v, %q), want (%v, %q)", gotAt, gotID, createdAt, id)
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	raw := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	tests := map[string]string{
+		"not base64":    "!!not-base64!!",
+		"no separator":  raw("2026-02-03T14:05:06Z"),
+		"bad timestamp": raw("yesterday|3f2b8c1e-9a4d-4e6f-8b7a-1c2d3e4f5a6b"),
+		"id not a UUID": raw("2026-02-03T14:05:06Z|1 OR 1=1"),
+		"empty id":      raw("2026-02-03T14:05:06Z|"),
+	}
+	for name, cursor := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := decodeCursor(cursor); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("decodeCursor(%q) = %v, want ErrInvalidCursor", cursor, err)
+			}
+		})
+	}
+}
+
+func TestPageLimit(t *testing.T) {
+	tests := []struct{ in, want int }{
+		{0, 20},
+		{-5, 20},
+		{1, 1},
+		{50, 50},
+		{100, 100},
+		{101, 100},
+		{5000, 100},
+	}
+	for _, tt := range tests {
+		if got := pageLimit(tt.in); got != tt.want {
+			t.Errorf("pageLimit(%d) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
@@ -2,71 +2,252 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
 	"leaseCar/payment-service/internal/dtos"
 	"leaseCar/payment-service/internal/factory"
 	"leaseCar/payment-service/internal/repositories"
 	"leaseCar/payment-service/internal/strategies"
+	"leaseCar/payment-service/internal/webhooks"
 	redisutil "leaseCar/utils/redis"
 	"leaseCar/utils/logger"
 )
 
+const MaxIdempotencyKeyLength = 255
+
+var (
+	ErrInvalidIdempotencyKey    = fmt.Errorf("Idempotency-Key must be at most %d characters", MaxIdempotencyKeyLength)
+	ErrIdempotencyKeyUser       = errors.New("user_id must be a UUID when an Idempotency-Key is sent")
+	ErrIdempotencyKeyReused     = repositories.ErrIdempotencyKeyReused
+	ErrIdempotencyKeyInProgress = repositories.ErrIdempotencyKeyInProgress
+	ErrIdempotencyKeyFailed     = repositories.ErrIdempotencyKeyFailed
+	// ErrPaymentCancelled means the payment was cancelled while the provider
+	// was charging it; the charge has been reversed.
+	ErrPaymentCancelled = errors.New("payment was cancelled while it was being processed")
+	ErrUnknownProvider = errors.New("unknown payment provider")
+	// ErrWebhookPaymentNotFound means the event is stored but its payment
+	// isn't known yet; the provider's redelivery processes it.
+	ErrWebhookPaymentNotFound = errors.New("payment for webhook event not found")
+)
+
+// webhookTransitions lists the statuses a payment may be in for a webhook
+// to move it to each status; anything else is stale or out of order.
+var webhookTransitions = map[string][]string{
+	dtos.PaymentStatusProcessing: {dtos.PaymentStatusPending},
+	dtos.PaymentStatusCompleted:  {dtos.PaymentStatusPending, dtos.PaymentStatusProcessing},
+	dtos.PaymentStatusFailed:     {dtos.PaymentStatusPending, dtos.PaymentStatusProcessing},
+	dtos.PaymentStatusCancelled:  {dtos.PaymentStatusPending, dtos.PaymentStatusProcessing},
+	dtos.PaymentStatusRefunded:   {dtos.PaymentStatusCompleted},
+}
+
+// paymentStore is the part of PaymentRepository the service uses, so tests
+// can substitute a fake.
+type paymentStore interface {
+	Create(ctx context.Context, req *dtos.PaymentRequest) (string, error)
+	UpdateStatus(ctx context.Context, id, status, txHash, reversal string) (bool, error)
+	GetByID(ctx context.Context, id string) (*dtos.Payment, error)
+	ListByLease(ctx context.Context, leaseID string) ([]dtos.Payment, error)
+	ListByUser(ctx context.Context, userID, status string, from, to, afterCreatedAt *time.Time, afterID string, limit int) ([]dtos.Payment, error)
+	Cancel(ctx context.Context, id, reason string) (*dtos.Payment, error)
+	RecordReversal(ctx context.Context, id, status, message string) error
+	ListReversals(ctx context.Context, status string, before *time.Time, limit int) ([]dtos.Payment, error)
+	RetryReversal(ctx context.Context, id string) (*dtos.Payment, error)
+}
+
+// strategySource resolves providers by name, like PaymentFactory.
+type strategySource interface {
+	GetStrategy(provider string) strategies.PaymentStrategy
+	GetWebhookProvider(provider string) webhooks.Provider
+}
+
+// publisher publishes events, like the Redis client.
+type publisher interface {
+	Publish(ctx context.Context, channel string, message interface{}) error
+}
+
 type PaymentService struct {
-	repo *repositories.PaymentRepository
-	factory *factory.PaymentFactory
-	redisClient *redisutil.Client
+	repo paymentStore
+	webhooks *repositories.WebhookRepository
+	idempotency *repositories.IdempotencyRepository
+	refunds *repositories.RefundRepository
+	factory strategySource
+	redisClient publisher
 }
 
-func NewPaymentService(repo *repositories.PaymentRepository, factory *factory.PaymentFactory, r *redisutil.Client) *PaymentService {
-	return &PaymentService{repo: repo, factory: factory, redisClient: r}
+func NewPaymentService(repo *repositories.PaymentRepository, webhooks *repositories.WebhookRepository, idempotency *repositories.IdempotencyRepository, refunds *repositories.RefundRepository, factory *factory.PaymentFactory, r *redisutil.Client) *PaymentService {
+	return &PaymentService{repo: repo, webhooks: webhooks, idempotency: idempotency, refunds: refunds, factory: factory, redisClient: r}
+}
+
+// CreatePaymentIdempotent creates the payment once per user and
+// Idempotency-Key and returns the original response on retries; replayed
+// reports a retry. Retries while the first request is running, or after it
+// failed once the provider may have charged, are refused rather than
+// charged again. Failures before the provider is asked release the key.
+// Without a key it behaves like CreatePayment.
+func (s *PaymentService) CreatePaymentIdempotent(ctx context.Context, key string, req *dtos.PaymentRequest) (*dtos.PaymentResponse, bool, error) {
+	if key == "" {
+		resp, err := s.CreatePayment(ctx, req)
+		return resp, false, err
+	}
+	if len(key) > MaxIdempotencyKeyLength {
+		return nil, false, ErrInvalidIdempotencyKey
+	}
+	if _, err := uuid.Parse(req.UserID); err != nil {
+		return nil, false, ErrIdempotencyKeyUser
+	}
+	// hash the parsed request so formatting differences don't count
+	b, err := json.Marshal(req)
+	if err != nil {
+		return nil, false, err
+	}
+	sum := sha256.Sum256(b)
+
+	keyed := *req
+	keyed.IdempotencyKey = key
+	_, body, replayed, err := s.idempotency.Once(ctx, req.UserID, key, hex.EncodeToString(sum[:]), func() (int, []byte, string, error) {
+		resp, charged, err := s.createPayment(ctx, &keyed)
+		if err != nil && !charged {
+			return 0, nil, "", &repositories.NotChargedError{Err: err}
+		}
+		if err != nil {
+			return 0, nil, "", err
+		}
+		b, err := json.Marshal(resp)
+		return 201, b, resp.PaymentID, err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	var resp dtos.PaymentResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, false, err
+	}
+	return &resp, replayed, nil
 }
 
 func (s *PaymentService) CreatePayment(ctx context.Context, req *dtos.PaymentRequest) (*dtos.PaymentResponse, error) {
+	resp, _, err := s.createPayment(ctx, req)
+	return resp, err
+}
+
+// createPayment records and charges a payment. charged reports whether the
+// provider was asked to charge, which is unknown-outcome territory when err
+// is set.
+func (s *PaymentService) createPayment(ctx context.Context, req *dtos.PaymentRequest) (resp *dtos.PaymentResponse, charged bool, err error) {
 	// validate and create record
 	id, err := s.repo.Create(ctx, req)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	// pick strategy
 	strat := s.factory.GetStrategy(req.Provider)
 	if strat == nil {
-		return nil, fmt.Errorf("no strategy for provider %s", req.Provider)
+		return nil, false, fmt.Errorf("no strategy for provider %s", req.Provider)
 	}
 
 	if err := strat.Validate(req); err != nil {
-		s.repo.UpdateStatus(ctx, id, "FAILED", "")
-		return nil, err
+		s.repo.UpdateStatus(ctx, id, "FAILED", "", "")
+		return nil, false, err
 	}
 
 	// process
-	resp, err := strat.Process(ctx, req)
+	resp, err = strat.Process(ctx, req)
 	if err != nil {
-		s.repo.UpdateStatus(ctx, id, "FAILED", "")
-		return nil, err
+		s.repo.UpdateStatus(ctx, id, "FAILED", "", "")
+		return nil, true, err
 	}
 
 	// update record with provider tx id and status
 	status := resp.Status
 	tx := resp.ProviderTxID
-	s.repo.UpdateStatus(ctx, id, status, tx)
+	applied, err := s.repo.UpdateStatus(ctx, id, status, tx, reversalMethod(status))
+	if err != nil {
+		logger.Error("provider charged payment " + id + " but recording it failed: " + err.Error())
+		return nil, true, err
+	}
+	if !applied {
+		p, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, true, err
+		}
+		if p.Status == dtos.PaymentStatusCancelled {
+			// cancelled while the provider was charging; the reversal is
+			// queued, so a failure here is retried by Maintenance
+			if p.ReversalStatus != nil && *p.ReversalStatus == dtos.ReversalPending {
+				rctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+				defer cancel()
+				if err := s.reverseCharge(rctx, p); err != nil {
+					logger.Error("failed to reverse charge of cancelled payment " + id + ": " + err.Error())
+				}
+			}
+			return nil, true, ErrPaymentCancelled
+		}
+		// a webhook got there first and has already settled and announced it
+		resp.Status = p.Status
+		resp.PaymentID = id
+		return resp, true, nil
+	}
 
 	// emit event to redis for observer (blockchain-service)
-	event := map[string]interface{}{"event": "payment.completed", "payment_id": id, "provider_tx": tx, "status": status}
-	b, _ := json.Marshal(event)
-	if err := s.redisClient.Publish(context.Background(), "payments", string(b)); err != nil {
-		logger.Error("failed to publish payment event")
+	if status == dtos.PaymentStatusCompleted {
+		event := map[string]interface{}{"event": "payment.completed", "payment_id": id, "provider_tx": tx, "status": status}
+		b, _ := json.Marshal(event)
+		if err := s.redisClient.Publish(context.Background(), "payments", string(b)); err != nil {
+			logger.Error("failed to publish payment event")
+		}
 	}
 
 	resp.PaymentID = id
-	return resp, nil
+	return resp, true, nil
 }
 
-func (s *PaymentService) HandleProviderWebhook(ctx context.Context, provider string, payload map[string]interface{}) error {
-	// For simplicity, provider-specific parsing omitted
-	logger.Info("received webhook", logger.WithFields())
-	return nil
+// HandleProviderWebhook verifies a provider event, stores it in the
+// payment_webhooks inbox and applies it to its payment once. Redelivered
+// events are acknowledged without being applied again.
+func (s *PaymentService) HandleProviderWebhook(ctx context.Context, provider string, header func(string) string, body []byte) (*dtos.WebhookResult, error) {
+	p := s.factory.GetWebhookProvider(provider)
+	if p == nil {
+		return nil, ErrUnknownProvider
+	}
+	if err := p.Verify(header, body, time.Now()); err != nil {
+		logger.Warn("rejected " + provider + " webhook: " + err.Error())
+		return nil, err
+	}
+	ev, err := p.Parse(body)
+	if err != nil {
+		return nil, err
+	}
+
+	id, processed, err := s.webhooks.Save(ctx, ev)
+	if err != nil {
+		return nil, err
+	}
+	if processed {
+		return &dtos.WebhookResult{WebhookID: id, EventID: ev.EventID, Duplicate: true}, nil
+	}
+	res, err := s.webhooks.Apply(ctx, id, ev, webhookTransitions[ev.Status])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrWebhookPaymentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Applied && res.Status == dtos.PaymentStatusCompleted {
+		event := map[string]interface{}{"event": "payment.completed", "payment_id": res.PaymentID, "provider_tx": ev.TransactionID, "status": res.Status}
+		b, _ := json.Marshal(event)
+		if err := s.redisClient.Publish(context.Background(), "payments", string(b)); err != nil {
+			logger.Error("failed to publish payment event")
+		}
+	}
+	return res, nil
 }
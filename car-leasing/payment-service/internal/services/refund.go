@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"leaseCar/payment-service/internal/dtos"
+	"leaseCar/utils/logger"
+)
+
+var (
+	ErrInvalidRefundAmount   = errors.New("refund amount must be positive with at most two decimals")
+	ErrRefundExceedsCaptured = errors.New("refund exceeds the captured amount still refundable")
+	ErrRefundDeclined        = errors.New("provider declined the refund")
+)
+
+// refundStaleAfter is how long a refund may stay PENDING before it is
+// resubmitted to the provider.
+const refundStaleAfter = 5 * time.Minute
+
+// PaymentNotRefundableError reports a payment whose status has nothing
+// captured to refund.
+type PaymentNotRefundableError struct {
+	Status string
+}
+
+func (e *PaymentNotRefundableError) Error() string {
+	return "payment is " + e.Status + "; only COMPLETED payments can be refunded"
+}
+
+// ProviderError wraps a refund or void the payment provider declined or
+// failed.
+type ProviderError struct {
+	Err error
+}
+
+func (e *ProviderError) Error() string { return "payment provider failed: " + e.Err.Error() }
+func (e *ProviderError) Unwrap() error { return e.Err }
+
+func cents(amount float64) int64 { return int64(math.Round(amount * 100)) }
+
+// RefundPayment refunds all (amount 0) or part of a COMPLETED payment. The
+// refund is reserved against the payment before the provider is called, so
+// concurrent refunds can never add up to more than was captured. A failed
+// or declined provider call leaves a FAILED refund and releases its amount;
+// a refund the provider has not settled yet is returned PENDING.
+func (s *PaymentService) RefundPayment(ctx context.Context, paymentID string, in *dtos.RefundRequest) (*dtos.RefundResult, error) {
+	if in.Amount < 0 || math.Abs(in.Amount*100-math.Round(in.Amount*100)) > 1e-6 {
+		return nil, ErrInvalidRefundAmount
+	}
+	if !isUUID(paymentID) {
+		return nil, ErrPaymentNotFound
+	}
+
+	ref, p, err := s.refunds.Reserve(ctx, paymentID, in.Reason, func(p *dtos.Payment) (float64, error) {
+		return refundAmount(p, in.Amount)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrPaymentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ref, p, err = s.submitRefund(ctx, p, ref)
+	if err != nil {
+		return nil, err
+	}
+	return &dtos.RefundResult{Refund: ref, Payment: p}, nil
+}
+
+// refundAmount is how much of the locked payment p a refund of requested
+// (0 for the rest) reserves. Only COMPLETED payments are refundable, and
+// refunds already reserved count against what was captured.
+func refundAmount(p *dtos.Payment, requested float64) (float64, error) {
+	if p.Status != dtos.PaymentStatusCompleted {
+		return 0, &PaymentNotRefundableError{Status: p.Status}
+	}
+	remaining := cents(p.Amount) - cents(p.RefundedAmount)
+	amount := cents(requested)
+	if amount == 0 {
+		amount = remaining
+	}
+	if amount <= 0 || amount > remaining {
+		return 0, fmt.Errorf("%w (%.2f of %.2f left)", ErrRefundExceedsCaptured, float64(remaining)/100, p.Amount)
+	}
+	return float64(amount) / 100, nil
+}
+
+// refundOutcome is what the provider's answer means for a refund.
+type refundOutcome int
+
+const (
+	refundSucceeded refundOutcome = iota
+	refundDeclined
+	refundInFlight
+)
+
+// classifyRefund reads the provider's answer to a refund. Only an explicit
+// SUCCEEDED completes it and only an error or an explicit FAILED releases
+// it; anything else may still go through, so the refund stays reserved.
+func classifyRefund(res *dtos.ProviderRefundResponse, err error) refundOutcome {
+	switch {
+	case err != nil:
+		return refundDeclined
+	case res == nil:
+		return refundInFlight
+	case res.Status == dtos.RefundSucceeded:
+		return refundSucceeded
+	case res.Status == dtos.RefundFailed:
+		return refundDeclined
+	}
+	return refundInFlight
+}
+
+// submitRefund asks the provider for a reserved refund and records its
+// answer. The refund id is the provider's idempotency key, so submitting the
+// same refund again never pays it twice. A declined refund is marked FAILED
+// and releases its amount; one the provider has not settled stays PENDING
+// for ReconcileRefunds. A succeeded refund publishes payment.refunded.
+func (s *PaymentService) submitRefund(ctx context.Context, p *dtos.Payment, ref *dtos.Refund) (*dtos.Refund, *dtos.Payment, error) {
+	var res *dtos.ProviderRefundResponse
+	strat := s.factory.GetStrategy(strings.ToLower(p.Provider))
+	err := fmt.Errorf("no strategy for provider %s", p.Provider)
+	if strat != nil {
+		req := &dtos.ProviderRefundRequest{PaymentID: p.ID, RefundID: ref.ID, Amount: ref.Amount, Currency: ref.Currency, Reason: ref.Reason}
+		if p.TransactionID != nil {
+			req.ProviderTxID = *p.TransactionID
+		}
+		res, err = strat.Refund(ctx, req)
+	}
+
+	switch classifyRefund(res, err) {
+	case refundDeclined:
+		if err == nil {
+			err = ErrRefundDeclined
+		}
+		// release the reservation on a fresh context; ctx may be what failed
+		if _, ferr := s.refunds.Fail(context.Background(), ref.ID, err.Error()); ferr != nil {
+			logger.Error("failed to release refund " + ref.ID + ": " + ferr.Error())
+		}
+		return nil, nil, &ProviderError{Err: err}
+	case refundInFlight:
+		ref, err = s.refunds.Submitted(ctx, ref.ID, res.ProviderRefundID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return ref, p, nil
+	}
+
+	// if this fails the refund stays PENDING and ReconcileRefunds completes it
+	ref, err = s.refunds.Complete(ctx, ref.ID, res.ProviderRefundID)
+	if err != nil {
+		return nil, nil, err
+	}
+	p, err = s.repo.GetByID(ctx, p.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	event := dtos.PaymentRefundedEvent{Event: "payment.refunded", PaymentID: p.ID, RefundID: ref.ID, Amount: ref.Amount, RefundedAmount: p.RefundedAmount, Status: p.Status}
+	b, _ := json.Marshal(event)
+	if err := s.redisClient.Publish(context.Background(), "payments", string(b)); err != nil {
+		logger.Error("failed to publish refund event")
+	}
+	return ref, p, nil
+}
+
+// ReconcileRefunds resubmits refunds left PENDING for longer than
+// refundStaleAfter: ones the provider answered as pending, and ones whose
+// completion failed to be recorded. It returns how many it settled either
+// way.
+func (s *PaymentService) ReconcileRefunds(ctx context.Context) (int, error) {
+	refunds, err := s.refunds.ListStale(ctx, time.Now().Add(-refundStaleAfter), 50)
+	if err != nil {
+		return 0, err
+	}
+	settled := 0
+	for i := range refunds {
+		ref := &refunds[i]
+		p, err := s.repo.GetByID(ctx, ref.PaymentID)
+		if err != nil {
+			return settled, err
+		}
+		done, _, err := s.submitRefund(ctx, p, ref)
+		var providerErr *ProviderError
+		switch {
+		case errors.As(err, &providerErr):
+			settled++
+		case err != nil:
+			logger.Warn("failed to reconcile refund " + ref.ID + ": " + err.Error())
+		case done.Status != dtos.RefundPending:
+			settled++
+		}
+	}
+	return settled, nil
+}
+
+func (s *PaymentService) ListRefunds(ctx context.Context, paymentID string) ([]dtos.Refund, error) {
+	if _, err := s.GetPayment(ctx, paymentID); err != nil {
+		return nil, err
+	}
+	return s.refunds.ListByPayment(ctx, paymentID)
+}
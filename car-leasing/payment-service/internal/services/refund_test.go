@@ -0,0 +1,91 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"leaseCar/payment-service/internal/dtos"
+)
+
+func TestRefundAmount(t *testing.T) {
+	payment := func(status string, amount, refunded float64) *dtos.Payment {
+		return &dtos.Payment{Status: status, Amount: amount, RefundedAmount: refunded}
+	}
+	tests := []struct {
+		name      string
+		payment   *dtos.Payment
+		requested float64
+		want      float64
+		err       error
+	}{
+		{name: "full refund", payment: payment(dtos.PaymentStatusCompleted, 450, 0), want: 450},
+		{name: "partial refund", payment: payment(dtos.PaymentStatusCompleted, 450, 0), requested: 120.25, want: 120.25},
+		{name: "rest after a partial refund", payment: payment(dtos.PaymentStatusCompleted, 450, 120.25), want: 329.75},
+		{name: "exactly the rest", payment: payment(dtos.PaymentStatusCompleted, 0.3, 0.1), requested: 0.2, want: 0.2},
+		{name: "more than captured", payment: payment(dtos.PaymentStatusCompleted, 450, 0), requested: 450.01, err: ErrRefundExceedsCaptured},
+		{name: "more than is left", payment: payment(dtos.PaymentStatusCompleted, 450, 400), requested: 60, err: ErrRefundExceedsCaptured},
+		{name: "nothing left", payment: payment(dtos.PaymentStatusCompleted, 450, 450), err: ErrRefundExceedsCaptured},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := refundAmount(tt.payment, tt.requested)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("refundAmount error = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("refundAmount = %.2f, want %.2f", got, tt.want)
+			}
+		})
+	}
+
+	for _, status := range []string{dtos.PaymentStatusPending, dtos.PaymentStatusProcessing, dtos.PaymentStatusFailed, dtos.PaymentStatusRefunded, dtos.PaymentStatusCancelled} {
+		var notRefundable *PaymentNotRefundableError
+		if _, err := refundAmount(payment(status, 450, 0), 0); !errors.As(err, &notRefundable) {
+			t.Errorf("%s payment: error = %v, want PaymentNotRefundableError", status, err)
+		}
+	}
+}
+
+// Reservations against the locked payment never add up to more than was
+// captured, however the requests are split.
+func TestRefundReservationsStayWithinCaptured(t *testing.T) {
+	p := &dtos.Payment{Status: dtos.PaymentStatusCompleted, Amount: 100}
+	reserved := 0.0
+	for _, requested := range []float64{33.33, 33.33, 33.33, 0.02, 0.01, 5, 0} {
+		amount, err := refundAmount(p, requested)
+		if err != nil {
+			continue
+		}
+		p.RefundedAmount += amount
+		reserved += amount
+	}
+	if cents(reserved) != 10000 {
+		t.Errorf("reserved %.2f, want exactly 100.00", reserved)
+	}
+	if _, err := refundAmount(p, 0.01); !errors.Is(err, ErrRefundExceedsCaptured) {
+		t.Errorf("refund after full reservation: error = %v, want ErrRefundExceedsCaptured", err)
+	}
+}
+
+func TestClassifyRefund(t *testing.T) {
+	tests := []struct {
+		name string
+		res  *dtos.ProviderRefundResponse
+		err  error
+		want refundOutcome
+	}{
+		{name: "succeeded", res: &dtos.ProviderRefundResponse{Status: dtos.RefundSucceeded}, want: refundSucceeded},
+		{name: "declined", res: &dtos.ProviderRefundResponse{Status: dtos.RefundFailed}, want: refundDeclined},
+		{name: "call failed", err: errors.New("connection reset"), want: refundDeclined},
+		{name: "pending", res: &dtos.ProviderRefundResponse{Status: dtos.RefundPending}, want: refundInFlight},
+		{name: "unknown status", res: &dtos.ProviderRefundResponse{Status: "requires_action"}, want: refundInFlight},
+		{name: "no answer", want: refundInFlight},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyRefund(tt.res, tt.err); got != tt.want {
+				t.Errorf("classifyRefund = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"leaseCar/payment-service/internal/dtos"
+	"leaseCar/utils/logger"
+)
+
+const (
+	// maxReversalAttempts is how often a reversal is tried before it is
+	// left FAILED for someone to act on.
+	maxReversalAttempts = 10
+	// reversalRetryAfter is how long a PENDING reversal waits between
+	// attempts.
+	reversalRetryAfter = time.Minute
+)
+
+var ErrNoFailedReversal = errors.New("payment has no failed reversal to retry")
+
+// reversalMethod is how to undo what the provider reported as status for a
+// payment cancelled meanwhile: a captured charge is refunded, anything still
+// in flight is voided, and a failed charge needs nothing.
+func reversalMethod(status string) string {
+	switch status {
+	case dtos.PaymentStatusCompleted:
+		return dtos.ReversalRefund
+	case dtos.PaymentStatusPending, dtos.PaymentStatusProcessing:
+		return dtos.ReversalVoid
+	}
+	return ""
+}
+
+// nextReversalStatus is the reversal status after the attempts-th attempt
+// ended with outcome. A refund the provider has not settled stays PENDING
+// without counting towards giving up.
+func nextReversalStatus(attempts int, outcome refundOutcome) string {
+	switch {
+	case outcome == refundSucceeded:
+		return dtos.ReversalReversed
+	case outcome == refundInFlight, attempts < maxReversalAttempts:
+		return dtos.ReversalPending
+	}
+	return dtos.ReversalFailed
+}
+
+// reverseCharge makes one attempt at reversing the charge of a cancelled
+// payment whose reversal is PENDING and records the outcome. Refunds use
+// the payment id as the refund's idempotency key, so retries never refund
+// twice.
+func (s *PaymentService) reverseCharge(ctx context.Context, p *dtos.Payment) error {
+	txID := ""
+	if p.TransactionID != nil {
+		txID = *p.TransactionID
+	}
+	method := ""
+	if p.ReversalMethod != nil {
+		method = *p.ReversalMethod
+	}
+
+	var outcome refundOutcome
+	var msg string
+	strat := s.factory.GetStrategy(strings.ToLower(p.Provider))
+	switch {
+	case strat == nil:
+		outcome, msg = refundDeclined, "no strategy for provider "+p.Provider
+	case method == dtos.ReversalRefund:
+		res, err := strat.Refund(ctx, &dtos.ProviderRefundRequest{PaymentID: p.ID, RefundID: p.ID, ProviderTxID: txID, Amount: p.Amount, Currency: p.Currency, Reason: "payment cancelled"})
+		switch outcome = classifyRefund(res, err); {
+		case err != nil:
+			msg = err.Error()
+		case outcome == refundDeclined:
+			msg = ErrRefundDeclined.Error()
+		case outcome == refundInFlight:
+			msg = "refund " + res.ProviderRefundID + " pending at the provider"
+		default:
+			msg = "refunded as " + res.ProviderRefundID
+		}
+	default:
+		outcome, msg = refundSucceeded, "voided"
+		if err := strat.Void(ctx, &dtos.ProviderVoidRequest{PaymentID: p.ID, ProviderTxID: txID, Reason: "payment cancelled"}); err != nil {
+			outcome, msg = refundDeclined, err.Error()
+		}
+	}
+
+	status := nextReversalStatus(p.ReversalAttempts+1, outcome)
+	switch status {
+	case dtos.ReversalReversed:
+		msg = "charged after cancellation; " + msg
+	case dtos.ReversalFailed:
+		msg = "charged after cancellation; reversal failed: " + msg
+		logger.Error("payment " + p.ID + " needs manual reversal: " + msg)
+	default:
+		msg = "charged after cancellation; reversal pending: " + msg
+	}
+	return s.repo.RecordReversal(ctx, p.ID, status, msg)
+}
+
+// RetryReversals makes another attempt at reversals left PENDING for at
+// least reversalRetryAfter and returns how many were attempted.
+func (s *PaymentService) RetryReversals(ctx context.Context) (int, error) {
+	before := time.Now().Add(-reversalRetryAfter)
+	payments, err := s.repo.ListReversals(ctx, dtos.ReversalPending, &before, 50)
+	if err != nil {
+		return 0, err
+	}
+	for i := range payments {
+		if err := s.reverseCharge(ctx, &payments[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(payments), nil
+}
+
+// ListReversals returns payments whose reversal is in status, FAILED when
+// empty.
+func (s *PaymentService) ListReversals(ctx context.Context, status string) ([]dtos.Payment, error) {
+	status = strings.ToUpper(status)
+	if status == "" {
+		status = dtos.ReversalFailed
+	}
+	switch status {
+	case dtos.ReversalPending, dtos.ReversalReversed, dtos.ReversalFailed:
+	default:
+		return nil, &InvalidFilterError{Param: "status", Reason: "must be PENDING, REVERSED or FAILED"}
+	}
+	return s.repo.ListReversals(ctx, status, nil, 100)
+}
+
+// RetryReversal puts a FAILED reversal back to PENDING and attempts it once
+// more; if that fails too, Maintenance keeps retrying it.
+func (s *PaymentService) RetryReversal(ctx context.Context, id string) (*dtos.Payment, error) {
+	if !isUUID(id) {
+		return nil, ErrPaymentNotFound
+	}
+	p, err := s.repo.RetryReversal(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if _, err := s.GetPayment(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrNoFailedReversal
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.reverseCharge(ctx, p); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
@@ -36,3 +36,20 @@ func (s *BankStrategy) Process(ctx context.Context, req *dtos.PaymentRequest) (*
 		CreatedAt:    time.Now(),
 	}, nil
 }
+
+func (s *BankStrategy) Refund(ctx context.Context, req *dtos.ProviderRefundRequest) (*dtos.ProviderRefundResponse, error) {
+	logger.Info("BankStrategy.Refund start")
+	res, err := s.adapter.SendRefund(req)
+	if err != nil {
+		return nil, err
+	}
+	return &dtos.ProviderRefundResponse{
+		ProviderRefundID: res.TransactionID,
+		Status:           dtos.RefundSucceeded,
+	}, nil
+}
+
+func (s *BankStrategy) Void(ctx context.Context, req *dtos.ProviderVoidRequest) error {
+	logger.Info("BankStrategy.Void start")
+	return s.adapter.CancelTransfer(req)
+}
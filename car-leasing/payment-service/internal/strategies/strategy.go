@@ -8,4 +8,11 @@ import (
 type PaymentStrategy interface {
 	Process(ctx context.Context, req *dtos.PaymentRequest) (*dtos.PaymentResponse, error)
 	Validate(req *dtos.PaymentRequest) error
+	// Refund returns part or all of a captured charge to the payer. The same
+	// refund may be submitted again while it is pending, so req.RefundID must
+	// be sent as the provider's idempotency key. The response status is
+	// SUCCEEDED, FAILED (declined) or PENDING (not settled yet).
+	Refund(ctx context.Context, req *dtos.ProviderRefundRequest) (*dtos.ProviderRefundResponse, error)
+	// Void stops a charge or transfer that hasn't been captured yet.
+	Void(ctx context.Context, req *dtos.ProviderVoidRequest) error
 }
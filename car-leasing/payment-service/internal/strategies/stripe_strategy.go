@@ -2,6 +2,7 @@ package strategies
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"leaseCar/payment-service/internal/dtos"
@@ -34,3 +35,23 @@ func (s *StripeStrategy) Process(ctx context.Context, req *dtos.PaymentRequest)
 	logger.Info("StripeStrategy.Process done")
 	return resp, nil
 }
+
+func (s *StripeStrategy) Refund(ctx context.Context, req *dtos.ProviderRefundRequest) (*dtos.ProviderRefundResponse, error) {
+	logger.Info("StripeStrategy.Refund start")
+	if req.ProviderTxID == "" {
+		return nil, errors.New("payment has no stripe charge to refund")
+	}
+	// Simulate network call; a real request sends Idempotency-Key: req.RefundID
+	time.Sleep(300 * time.Millisecond)
+	return &dtos.ProviderRefundResponse{
+		ProviderRefundID: "stripe_re_" + time.Now().Format("20060102150405"),
+		Status:           dtos.RefundSucceeded,
+	}, nil
+}
+
+func (s *StripeStrategy) Void(ctx context.Context, req *dtos.ProviderVoidRequest) error {
+	logger.Info("StripeStrategy.Void start")
+	// Simulate cancelling the payment intent
+	time.Sleep(200 * time.Millisecond)
+	return nil
+}
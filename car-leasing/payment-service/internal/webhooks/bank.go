@@ -0,0 +1,64 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"leaseCar/payment-service/internal/dtos"
+)
+
+var bankStatuses = map[string]string{
+	"transfer.pending":   dtos.PaymentStatusProcessing,
+	"transfer.completed": dtos.PaymentStatusCompleted,
+	"transfer.failed":    dtos.PaymentStatusFailed,
+	"transfer.rejected":  dtos.PaymentStatusFailed,
+	"transfer.cancelled": dtos.PaymentStatusCancelled,
+	"transfer.returned":  dtos.PaymentStatusRefunded,
+}
+
+// BankProvider checks X-Bank-Signature, the hex HMAC-SHA256 of the body
+// under the secret shared with the bank.
+type BankProvider struct {
+	secret string
+}
+
+func NewBankProvider(secret string) *BankProvider { return &BankProvider{secret: secret} }
+
+func (p *BankProvider) Verify(header func(string) string, body []byte, now time.Time) error {
+	if p.secret == "" {
+		return fmt.Errorf("%w: no webhook secret configured", ErrSignature)
+	}
+	sig := header("X-Bank-Signature")
+	if sig == "" {
+		return fmt.Errorf("%w: missing X-Bank-Signature", ErrSignature)
+	}
+	if !matches(sign(p.secret, body), sig) {
+		return fmt.Errorf("%w: signature mismatch", ErrSignature)
+	}
+	return nil
+}
+
+func (p *BankProvider) Parse(body []byte) (*dtos.WebhookEvent, error) {
+	var evt struct {
+		EventID       string `json:"event_id"`
+		EventType     string `json:"event_type"`
+		TransactionID string `json:"transaction_id"`
+		PaymentID     string `json:"reference"`
+	}
+	if err := json.Unmarshal(body, &evt); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	if evt.EventID == "" || evt.EventType == "" {
+		return nil, fmt.Errorf("%w: event_id and event_type are required", ErrMalformed)
+	}
+	return &dtos.WebhookEvent{
+		Provider:      "bank_api",
+		EventID:       evt.EventID,
+		EventType:     evt.EventType,
+		TransactionID: evt.TransactionID,
+		PaymentID:     evt.PaymentID,
+		Status:        bankStatuses[evt.EventType],
+		Data:          body,
+	}, nil
+}
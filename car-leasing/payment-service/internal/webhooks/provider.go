@@ -0,0 +1,39 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"leaseCar/payment-service/internal/dtos"
+)
+
+var (
+	// ErrSignature is wrapped by every verification failure.
+	ErrSignature = errors.New("webhook signature verification failed")
+	// ErrMalformed is wrapped when a verified body is not a usable event.
+	ErrMalformed = errors.New("malformed webhook event")
+)
+
+// Provider verifies and parses the webhooks of one payment provider.
+type Provider interface {
+	// Verify checks the request signature; header looks up request headers.
+	Verify(header func(string) string, body []byte, now time.Time) error
+	Parse(body []byte) (*dtos.WebhookEvent, error)
+}
+
+func sign(secret string, parts ...[]byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	for _, p := range parts {
+		mac.Write(p)
+	}
+	return mac.Sum(nil)
+}
+
+// matches compares a hex-encoded signature in constant time.
+func matches(expected []byte, signature string) bool {
+	got, err := hex.DecodeString(signature)
+	return err == nil && hmac.Equal(expected, got)
+}
@@ -0,0 +1,113 @@
+package webhooks
+
+import (
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const testSecret = "whsec_test"
+
+var testBody = []byte(`{"id":"evt_1","type":"charge.succeeded","data":{"object":{"id":"ch_1"}}}`)
+
+func headers(h map[string]string) func(string) string {
+	return func(k string) string { return h[k] }
+}
+
+func stripeSig(secret string, ts time.Time, body []byte) string {
+	t := strconv.FormatInt(ts.Unix(), 10)
+	return hex.EncodeToString(sign(secret, []byte(t), []byte("."), body))
+}
+
+func TestStripeProviderVerify(t *testing.T) {
+	now := time.Unix(1760000000, 0)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	valid := stripeSig(testSecret, now, testBody)
+	stale := now.Add(-DefaultStripeTolerance - time.Second)
+
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		body   []byte
+		ok     bool
+	}{
+		{name: "valid", secret: testSecret, header: "t=" + ts + ",v1=" + valid, body: testBody, ok: true},
+		{name: "valid with spaces", secret: testSecret, header: "t=" + ts + ", v1=" + valid, body: testBody, ok: true},
+		{name: "tampered body", secret: testSecret, header: "t=" + ts + ",v1=" + valid, body: []byte(`{"id":"evt_2"}`)},
+		{name: "wrong secret", secret: "other", header: "t=" + ts + ",v1=" + valid, body: testBody},
+		{name: "tampered timestamp", secret: testSecret, header: "t=" + strconv.FormatInt(now.Unix()-1, 10) + ",v1=" + valid, body: testBody},
+		{name: "stale", secret: testSecret,
+			header: "t=" + strconv.FormatInt(stale.Unix(), 10) + ",v1=" + stripeSig(testSecret, stale, testBody), body: testBody},
+		{name: "too far in the future", secret: testSecret,
+			header: "t=" + strconv.FormatInt(now.Add(time.Hour).Unix(), 10) + ",v1=" + stripeSig(testSecret, now.Add(time.Hour), testBody), body: testBody},
+		{name: "missing header", secret: testSecret, header: "", body: testBody},
+		{name: "missing timestamp", secret: testSecret, header: "v1=" + valid, body: testBody},
+		{name: "missing signature", secret: testSecret, header: "t=" + ts, body: testBody},
+		{name: "bad timestamp", secret: testSecret, header: "t=abc,v1=" + valid, body: testBody},
+		{name: "multiple v1, one valid", secret: testSecret, header: "t=" + ts + ",v1=deadbeef,v1=" + valid, body: testBody, ok: true},
+		{name: "multiple v1, none valid", secret: testSecret, header: "t=" + ts + ",v1=deadbeef,v1=" + stripeSig("other", now, testBody), body: testBody},
+		{name: "non-hex signature", secret: testSecret, header: "t=" + ts + ",v1=zz", body: testBody},
+		{name: "no secret configured", secret: "", header: "t=" + ts + ",v1=" + valid, body: testBody},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewStripeProvider(tt.secret, 0)
+			err := p.Verify(headers(map[string]string{"Stripe-Signature": tt.header}), tt.body, now)
+			if tt.ok && err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrSignature) {
+				t.Fatalf("Verify = %v, want ErrSignature", err)
+			}
+		})
+	}
+}
+
+func TestStripeProviderTolerance(t *testing.T) {
+	now := time.Unix(1760000000, 0)
+	signed := now.Add(-90 * time.Second)
+	header := headers(map[string]string{
+		"Stripe-Signature": "t=" + strconv.FormatInt(signed.Unix(), 10) + ",v1=" + stripeSig(testSecret, signed, testBody),
+	})
+	if err := NewStripeProvider(testSecret, 2*time.Minute).Verify(header, testBody, now); err != nil {
+		t.Fatalf("within tolerance: %v", err)
+	}
+	if err := NewStripeProvider(testSecret, time.Minute).Verify(header, testBody, now); !errors.Is(err, ErrSignature) {
+		t.Fatalf("outside tolerance: got %v, want ErrSignature", err)
+	}
+}
+
+func TestBankProviderVerify(t *testing.T) {
+	valid := hex.EncodeToString(sign(testSecret, testBody))
+
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		body   []byte
+		ok     bool
+	}{
+		{name: "valid", secret: testSecret, header: valid, body: testBody, ok: true},
+		{name: "tampered body", secret: testSecret, header: valid, body: []byte(`{"event_id":"2"}`)},
+		{name: "wrong secret", secret: "other", header: valid, body: testBody},
+		{name: "missing header", secret: testSecret, header: "", body: testBody},
+		{name: "non-hex signature", secret: testSecret, header: "zz", body: testBody},
+		{name: "truncated signature", secret: testSecret, header: valid[:32], body: testBody},
+		{name: "no secret configured", secret: "", header: valid, body: testBody},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewBankProvider(tt.secret)
+			err := p.Verify(headers(map[string]string{"X-Bank-Signature": tt.header}), tt.body, time.Now())
+			if tt.ok && err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrSignature) {
+				t.Fatalf("Verify = %v, want ErrSignature", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"leaseCar/payment-service/internal/dtos"
+)
+
+const DefaultStripeTolerance = 5 * time.Minute
+
+var stripeStatuses = map[string]string{
+	"payment_intent.processing":     dtos.PaymentStatusProcessing,
+	"payment_intent.succeeded":      dtos.PaymentStatusCompleted,
+	"charge.succeeded":              dtos.PaymentStatusCompleted,
+	"payment_intent.payment_failed": dtos.PaymentStatusFailed,
+	"charge.failed":                 dtos.PaymentStatusFailed,
+	"payment_intent.canceled":       dtos.PaymentStatusCancelled,
+	"charge.refunded":               dtos.PaymentStatusRefunded,
+}
+
+// StripeProvider checks the Stripe-Signature header: "t=<unix>,v1=<hex>"
+// where v1 is HMAC-SHA256 of "<t>.<body>" under the endpoint secret.
+type StripeProvider struct {
+	secret    string
+	tolerance time.Duration
+}
+
+func NewStripeProvider(secret string, tolerance time.Duration) *StripeProvider {
+	if tolerance <= 0 {
+		tolerance = DefaultStripeTolerance
+	}
+	return &StripeProvider{secret: secret, tolerance: tolerance}
+}
+
+func (p *StripeProvider) Verify(header func(string) string, body []byte, now time.Time) error {
+	if p.secret == "" {
+		return fmt.Errorf("%w: no webhook secret configured", ErrSignature)
+	}
+	var ts string
+	var sigs []string
+	for _, part := range strings.Split(header("Stripe-Signature"), ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sigs = append(sigs, v)
+		}
+	}
+	if ts == "" || len(sigs) == 0 {
+		return fmt.Errorf("%w: missing Stripe-Signature", ErrSignature)
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: bad timestamp", ErrSignature)
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > p.tolerance || age < -p.tolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrSignature)
+	}
+	expected := sign(p.secret, []byte(ts), []byte("."), body)
+	for _, s := range sigs {
+		if matches(expected, s) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: signature mismatch", ErrSignature)
+}
+
+func (p *StripeProvider) Parse(body []byte) (*dtos.WebhookEvent, error) {
+	var evt struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		Data struct {
+			Object struct {
+				ID       string            `json:"id"`
+				Metadata map[string]string `json:"metadata"`
+			} `json:"object"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &evt); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	if evt.ID == "" || evt.Type == "" {
+		return nil, fmt.Errorf("%w: id and type are required", ErrMalformed)
+	}
+	return &dtos.WebhookEvent{
+		Provider:      "stripe",
+		EventID:       evt.ID,
+		EventType:     evt.Type,
+		TransactionID: evt.Data.Object.ID,
+		PaymentID:     evt.Data.Object.Metadata["payment_id"],
+		Status:        stripeStatuses[evt.Type],
+		Data:          body,
+	}, nil
+}
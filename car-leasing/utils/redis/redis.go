@@ -8,6 +8,9 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// Nil is the error Get returns when the key does not exist.
+var Nil = redis.Nil
+
 type Client struct {
 	client *redis.Client
 }
@@ -35,11 +38,36 @@ func (c *Client) Set(ctx context.Context, key string, value interface{}, expirat
 	return c.client.Set(ctx, key, value, expiration).Err()
 }
 
+// SetNX sets a key only if it does not exist yet and reports whether it did
+func (c *Client) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	return c.client.SetNX(ctx, key, value, expiration).Result()
+}
+
 // Get retrieves a value by key
 func (c *Client) Get(ctx context.Context, key string) (string, error) {
 	return c.client.Get(ctx, key).Result()
 }
 
+// MGet retrieves several keys at once; missing keys come back as nil
+func (c *Client) MGet(ctx context.Context, keys ...string) ([]interface{}, error) {
+	return c.client.MGet(ctx, keys...).Result()
+}
+
+// ZRangeByScore returns the members of a sorted set scored between min and max
+func (c *Client) ZRangeByScore(ctx context.Context, key, min, max string) ([]string, error) {
+	return c.client.ZRangeByScore(ctx, key, &redis.ZRangeBy{Min: min, Max: max}).Result()
+}
+
+// ZRemRangeByScore removes the members of a sorted set scored between min and max
+func (c *Client) ZRemRangeByScore(ctx context.Context, key, min, max string) (int64, error) {
+	return c.client.ZRemRangeByScore(ctx, key, min, max).Result()
+}
+
+// Eval runs a Lua script atomically
+func (c *Client) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	return c.client.Eval(ctx, script, keys, args...).Result()
+}
+
 // Del deletes a key
 func (c *Client) Del(ctx context.Context, keys ...string) (int64, error) {
 	return c.client.Del(ctx, keys...).Result()